@@ -7,17 +7,16 @@ import (
 	"fmt"
 	"github.com/apex/log"
 	"github.com/nu7hatch/gouuid"
-	"github.com/pkg/errors"
 	"github.com/segmentio/go-source"
 	"github.com/segmentio/go-source/source-logger"
 	"github.com/segmentio/ur-log"
+	"io"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 )
 
-const stripeApiVersion = "2016-07-06"
-
 type clientImpl struct {
 	httpClient   HttpClient
 	baseUrl      string
@@ -25,19 +24,68 @@ type clientImpl struct {
 	throttler    *Throttler
 	sourceClient source.Client
 	sourceLogger SourceLogger
+	adapter      *VersionAdapter
+	// logListPayloads retains bodies of list responses for the source logger
+	logListPayloads bool
+	// debugLogging retains bodies of list responses for the debug log
+	debugLogging bool
+	quota        *Quota
 }
 
 func (c *clientImpl) GetList(ctx context.Context, req *Request) (*ObjectList, error) {
-	output := &listResponse{}
-	if err := c.get(ctx, req, output); err != nil {
+	objects := []Object{}
+	result, err := c.StreamList(ctx, req, func(obj Object) error {
+		objects = append(objects, obj)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Objects = objects
+	return result, nil
+}
+
+// StreamList decodes objects of a list one by one and passes them to fn as soon as they're read from
+// the response, so that only a single object of the page is held in memory. The returned list doesn't
+// include the objects. If fn fails, reading the response stops and its error is returned as is.
+func (c *clientImpl) StreamList(ctx context.Context, req *Request, fn func(Object) error) (*ObjectList, error) {
+	ctx, ex, err := c.exchange(ctx, "GET", req)
+	if err != nil {
 		return nil, err
 	}
+	defer ex.resp.Body.Close()
 
-	result := &ObjectList{
-		HasMore: output.HasMore,
+	if ex.resp.StatusCode != 200 {
+		_, _, err := c.readBody(ctx, ex)
+		return nil, err
 	}
-	for _, obj := range output.Data {
-		result.Objects = append(result.Objects, obj)
+
+	body := &countingReader{reader: ex.resp.Body}
+	var payload *bytes.Buffer
+	var reader io.Reader = body
+	if c.retainListPayloads() {
+		payload = &bytes.Buffer{}
+		reader = io.TeeReader(body, payload)
+	}
+
+	// time spent in fn isn't part of the response latency
+	var fnErr error
+	var busy time.Duration
+	result, err := decodeList(reader, func(obj Object) error {
+		c.adapter.Normalize(obj)
+		ts := time.Now()
+		fnErr = fn(obj)
+		busy += time.Now().Sub(ts)
+		return fnErr
+	})
+
+	ctx = c.logResponse(ctx, ex, body.count, time.Now().Sub(ex.started)-busy, payload)
+	if fnErr != nil {
+		return nil, fnErr
+	}
+	if err != nil {
+		return nil, urlog.WrapError(ctx, err, "error decoding response")
 	}
 
 	return result, nil
@@ -45,60 +93,175 @@ func (c *clientImpl) GetList(ctx context.Context, req *Request) (*ObjectList, er
 
 func (c *clientImpl) GetObject(ctx context.Context, req *Request) (Object, error) {
 	output := Object{}
-	if err := c.get(ctx, req, &output); err != nil {
+	if err := c.call(ctx, "GET", req, &output); err != nil {
 		return nil, err
 	}
 
+	c.adapter.Normalize(output)
 	return output, nil
 }
 
-func (c *clientImpl) prepareRequest(req *Request) (*http.Request, error) {
+// Post sends req.Params as a form-encoded body and returns the created or updated object.
+// Use WithIdempotencyKey before retrying a request, otherwise every attempt gets a new key.
+func (c *clientImpl) Post(ctx context.Context, req *Request) (Object, error) {
+	output := Object{}
+	if err := c.call(ctx, "POST", req, &output); err != nil {
+		return nil, err
+	}
+
+	c.adapter.Normalize(output)
+	return output, nil
+}
+
+// Delete returns the deleted object, req.Params are sent in the query string
+func (c *clientImpl) Delete(ctx context.Context, req *Request) (Object, error) {
+	output := Object{}
+	if err := c.call(ctx, "DELETE", req, &output); err != nil {
+		return nil, err
+	}
+
+	c.adapter.Normalize(output)
+	return output, nil
+}
+
+// StreamFile passes the body of a file, e.g. a report run's result hosted on files.stripe.com, to fn while
+// it's being read so that the file isn't held in memory. If fn fails, its error is returned as is.
+func (c *clientImpl) StreamFile(ctx context.Context, req *Request, fn func(io.Reader) error) error {
+	ctx, ex, err := c.exchange(ctx, "GET", req)
+	if err != nil {
+		return err
+	}
+	defer ex.resp.Body.Close()
+
+	if ex.resp.StatusCode != 200 {
+		_, _, err := c.readBody(ctx, ex)
+		return err
+	}
+
+	body := &countingReader{reader: ex.resp.Body}
+	err = fn(body)
+	c.logResponse(ctx, ex, body.count, time.Now().Sub(ex.started), nil)
+	return err
+}
+
+func (c *clientImpl) prepareRequest(method string, req *Request) (*http.Request, error) {
 	url := req.Url
 	if url[0] == '/' {
 		url = c.baseUrl + url
 	}
 
-	httpReq, err := http.NewRequest("GET", url, nil)
+	params := EncodeParams(req.Params)
+	for _, field := range req.Expand {
+		params.Add("expand[]", field)
+	}
+	var body io.Reader
+	if method == "POST" {
+		for key, value := range req.Qs {
+			params[key] = value
+		}
+		body = strings.NewReader(params.Encode())
+	}
+
+	httpReq, err := http.NewRequest(method, url, body)
 	if err != nil {
 		return nil, err
 	}
 
-	newQs := httpReq.URL.Query()
-	for key, value := range req.Qs {
-		newQs[key] = value
+	if method == "POST" {
+		httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	} else {
+		newQs := httpReq.URL.Query()
+		for key, value := range params {
+			newQs[key] = value
+		}
+		for key, value := range req.Qs {
+			newQs[key] = value
+		}
+		httpReq.URL.RawQuery = newQs.Encode()
 	}
-	httpReq.URL.RawQuery = newQs.Encode()
 
-	httpReq.Header.Set("Stripe-Version", stripeApiVersion)
+	httpReq.Header.Set("Stripe-Version", c.adapter.Version())
 	for key, value := range req.Headers {
 		httpReq.Header[key] = value
 	}
 	httpReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.secret))
 
+	if method == "POST" && httpReq.Header.Get(idempotencyKeyHeader) == "" {
+		uv4, err := uuid.NewV4()
+		if err != nil {
+			return nil, err
+		}
+		httpReq.Header.Set(idempotencyKeyHeader, uv4.String())
+	}
+
 	return httpReq, nil
 }
 
-func (c *clientImpl) get(ctx context.Context, req *Request, output interface{}) error {
-	httpReq, err := c.prepareRequest(req)
+// call performs a request and decodes its JSON response into output
+func (c *clientImpl) call(ctx context.Context, method string, req *Request, output interface{}) error {
+	ctx, buffer, err := c.send(ctx, method, req)
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(buffer.Bytes()))
+	decoder.UseNumber()
+	if err := decoder.Decode(output); err != nil {
+		return urlog.WrapError(ctx, err, "error decoding response")
+	}
+
+	return nil
+}
+
+// exchange is a request that was sent along with its response, the body must be closed by the caller
+type exchange struct {
+	req        *Request
+	url        string
+	uuid       string
+	started    time.Time
+	metricTags []string
+	resp       *http.Response
+}
+
+// send performs a request and returns the body of a successful response along with
+// a context carrying the request and response log fields
+func (c *clientImpl) send(ctx context.Context, method string, req *Request) (context.Context, *bytes.Buffer, error) {
+	ctx, ex, err := c.exchange(ctx, method, req)
+	if err != nil {
+		return ctx, nil, err
+	}
+	defer ex.resp.Body.Close()
+
+	return c.readBody(ctx, ex)
+}
+
+// exchange performs a request and returns the response without reading its body
+func (c *clientImpl) exchange(ctx context.Context, method string, req *Request) (context.Context, *exchange, error) {
+	httpReq, err := c.prepareRequest(method, req)
 	if err != nil {
 		ctx, _ := urlog.GetContextualLogger(ctx, nil, log.Fields{"request": req})
-		return urlog.WrapError(ctx, err, "failed to prepare request")
+		return ctx, nil, urlog.WrapError(ctx, err, "failed to prepare request")
 	}
 
 	uv4, err := uuid.NewV4()
 	if err != nil {
-		return urlog.WrapError(ctx, err, "failed to generate uuid")
+		return ctx, nil, urlog.WrapError(ctx, err, "failed to generate uuid")
 	}
 
 	ctx, logger := urlog.GetContextualLogger(ctx, nil, log.Fields{
 		"request": log.Fields{
 			"id":      uv4.String(),
+			"method":  method,
 			"url":     httpReq.URL.String(),
 			"headers": httpReq.Header,
 		},
 	})
 
-	c.throttler.Use()
+	share := shareFromContext(ctx)
+	if err := c.quota.use(); err != nil {
+		return ctx, nil, urlog.WrapError(ctx, err, "")
+	}
+	c.throttler.UseShare(share.name, share.weight, share.rps)
 
 	ts := time.Now()
 	logger.Info("http request")
@@ -109,66 +272,85 @@ func (c *clientImpl) get(ctx context.Context, req *Request, output interface{})
 		metricTags = append(metricTags, fmt.Sprintf("project:%s/%s", workspaceSlug, projectSlug))
 	}
 	c.sourceClient.StatsIncrement("stripe.requests", 1, metricTags)
+	// requests are counted against the budget of the share that they were throttled in
+	budget := share.name
+	if budget == "" {
+		budget = req.LogCollection
+	}
+	c.sourceClient.StatsIncrement("stripe.budget.used", 1, append([]string{fmt.Sprintf("collection:%s", budget)}, metricTags...))
 	resp, err := c.httpClient.Do(httpReq)
 	c.sourceLogger.RequestSent(req.LogCollection, httpReq.URL.String(), sourcelogger.Metadata{"uuid": uv4.String()})
 	if err != nil {
-		return urlog.WrapError(ctx, err, "error performing request")
+		return ctx, nil, urlog.WrapError(ctx, err, "error performing request")
 	}
-	defer resp.Body.Close()
 
+	return ctx, &exchange{
+		req:        req,
+		url:        httpReq.URL.String(),
+		uuid:       uv4.String(),
+		started:    ts,
+		metricTags: metricTags,
+		resp:       resp,
+	}, nil
+}
+
+// readBody reads the whole body of a response and returns it if the request succeeded
+func (c *clientImpl) readBody(ctx context.Context, ex *exchange) (context.Context, *bytes.Buffer, error) {
 	buffer := &bytes.Buffer{}
-	if _, err := buffer.ReadFrom(resp.Body); err != nil {
-		return urlog.WrapError(ctx, err, "error reading response")
+	if _, err := buffer.ReadFrom(ex.resp.Body); err != nil {
+		return ctx, nil, urlog.WrapError(ctx, err, "error reading response")
 	}
 
-	duration := time.Now().Sub(ts)
-	metricTags = append(metricTags,
+	ctx = c.logResponse(ctx, ex, int64(buffer.Len()), time.Now().Sub(ex.started), buffer)
+	if ex.resp.StatusCode == 200 {
+		return ctx, buffer, nil
+	}
+
+	return ctx, nil, urlog.WrapError(ctx, newStripeError(ex.resp, buffer.Bytes()), "")
+}
+
+// logResponse records response stats and logs the payload if it was retained
+func (c *clientImpl) logResponse(ctx context.Context, ex *exchange, size int64, duration time.Duration, payload *bytes.Buffer) context.Context {
+	resp := ex.resp
+	metricTags := append(ex.metricTags,
 		fmt.Sprintf("status_code:%d", resp.StatusCode),
 		fmt.Sprintf("status_code_bucket:%dxx", resp.StatusCode/100),
 	)
 	c.sourceClient.StatsIncrement("stripe.responses", 1, metricTags)
-	c.sourceClient.StatsHistogram("stripe.response.payload_size", int64(buffer.Len()), metricTags)
+	c.sourceClient.StatsHistogram("stripe.response.payload_size", size, metricTags)
 	c.sourceClient.StatsHistogram("stripe.response.latency", duration.Nanoseconds()/1000000, metricTags)
 
 	headersBuffer := &bytes.Buffer{}
 	resp.Header.Write(headersBuffer)
 	logMetadata := sourcelogger.Metadata{
-		"uuid":    uv4.String(),
+		"uuid":         ex.uuid,
+		"status":       resp.Status,
+		"headers":      headersBuffer.String(),
+		"payload_size": size,
+	}
+	responseFields := log.Fields{
+		"headers": resp.Header,
 		"status":  resp.Status,
-		"headers": headersBuffer.String(),
 	}
-	c.sourceLogger.ResponseReceived(req.LogCollection, httpReq.URL.String(), logMetadata, duration, buffer.String())
+	if payload != nil {
+		c.sourceLogger.ResponseReceived(ex.req.LogCollection, ex.url, logMetadata, duration, payload.String())
+		responseFields["body"] = payload.String()
+	} else {
+		c.sourceLogger.ResponseReceived(ex.req.LogCollection, ex.url, logMetadata, duration, nil)
+	}
 
-	ctx, logger = urlog.GetContextualLogger(ctx, logger, log.Fields{
-		"response": log.Fields{
-			"headers": resp.Header,
-			"status":  resp.Status,
-			"body":    buffer.String(),
-		},
+	ctx, logger := urlog.GetContextualLogger(ctx, nil, log.Fields{
+		"response": responseFields,
 	})
 	logger.Debug("http response")
 
-	if resp.StatusCode == 200 {
-		decoder := json.NewDecoder(bytes.NewReader(buffer.Bytes()))
-		decoder.UseNumber()
-		if err := decoder.Decode(output); err != nil {
-			return urlog.WrapError(ctx, err, "error decoding response")
-		}
-		return nil
-	}
-
-	err = urlog.WrapError(ctx, errors.New("unexpected response status code"), "")
-	if resp.StatusCode >= 500 || resp.StatusCode == 429 {
-		// transient errors
-		return err
-	}
+	return ctx
+}
 
-	// non-transient errors
-	isAuthRelated := resp.StatusCode == 401
-	return &permanentError{
-		wrappedError:  err.(wrappedError),
-		isAuthRelated: isAuthRelated,
-	}
+// retainListPayloads returns true if bodies of list responses are needed for logging,
+// otherwise lists are decoded without keeping the body in memory
+func (c *clientImpl) retainListPayloads() bool {
+	return c.logListPayloads || c.debugLogging
 }
 
 func NewClient(opts *ClientOptions) Client {
@@ -177,11 +359,15 @@ func NewClient(opts *ClientOptions) Client {
 	}
 
 	return &clientImpl{
-		httpClient:   opts.HttpClient,
-		baseUrl:      opts.BaseUrl,
-		secret:       opts.Secret,
-		throttler:    NewThrottler(opts.MaxRps, time.Second),
-		sourceClient: opts.SourceClient,
-		sourceLogger: opts.SourceClient.Log(),
+		httpClient:      opts.HttpClient,
+		baseUrl:         opts.BaseUrl,
+		secret:          opts.Secret,
+		throttler:       NewThrottler(opts.MaxRps, time.Second),
+		sourceClient:    opts.SourceClient,
+		sourceLogger:    opts.SourceClient.Log(),
+		adapter:         NewVersionAdapter(opts.ApiVersion),
+		logListPayloads: opts.LogListPayloads,
+		debugLogging:    opts.DebugLogging,
+		quota:           opts.Quota,
 	}
 }
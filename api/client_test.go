@@ -0,0 +1,29 @@
+package api
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/url"
+	"testing"
+)
+
+func TestPrepareRequestExpand(t *testing.T) {
+	c := &clientImpl{
+		baseUrl: "https://api.stripe.com",
+		adapter: NewVersionAdapter(""),
+	}
+
+	httpReq, err := c.prepareRequest("GET", &Request{
+		Url:    "/v1/charges?limit=100",
+		Expand: []string{"data.balance_transaction", "data.customer"},
+	})
+
+	a := assert.New(t)
+	if !a.NoError(err) {
+		return
+	}
+	a.Equal(url.Values{
+		"limit":    []string{"100"},
+		"expand[]": []string{"data.balance_transaction", "data.customer"},
+	}, httpReq.URL.Query())
+	a.Equal(DefaultApiVersion, httpReq.Header.Get("Stripe-Version"))
+}
@@ -1,17 +1,13 @@
 package api
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"github.com/apex/log"
-	"github.com/pkg/errors"
+	"net/http"
 )
 
-type wrappedError interface {
-	error
-	log.Fielder
-	StackTrace() errors.StackTrace
-	Cause() error
-}
-
 type causer interface {
 	Cause() error
 }
@@ -19,21 +15,94 @@ type causer interface {
 type errorInterface interface {
 	IsPermanent() bool
 	IsAuthRelated() bool
+	IsPermissionRelated() bool
+}
+
+// errorDescription is a description of a failed request returned by Stripe in the response body
+type errorDescription struct {
+	Type        string `json:"type"`
+	Code        string `json:"code"`
+	DeclineCode string `json:"decline_code"`
+	Param       string `json:"param"`
+	Message     string `json:"message"`
+}
+
+// StripeError is the root cause of every error returned for a response with an unexpected status code.
+// The client wraps it with urlog so that errors still implement urlog interface, and it can be found
+// in a wrapper chain by IsErrorPermanent, IsErrorAuthRelated and IsErrorPermissionRelated methods.
+type StripeError struct {
+	Type        string
+	Code        string
+	DeclineCode string
+	Param       string
+	Message     string
+	RequestId   string
+	StatusCode  int
+}
+
+// IsPermanent returns false for server errors and rate limiting since these are worth retrying
+func (e *StripeError) IsPermanent() bool {
+	return e.StatusCode < 500 && e.StatusCode != http.StatusTooManyRequests
+}
+
+func (e *StripeError) IsAuthRelated() bool {
+	return e.StatusCode == http.StatusUnauthorized
 }
 
-// permanentError wraps a urlog-compatible error so that it would still implement urlog interface
-// and could be used with IsErrorPermanent and IsErrorAuthRelated methods
-type permanentError struct {
-	wrappedError
-	isAuthRelated bool
+// IsPermissionRelated returns true if the key is valid but isn't allowed to access the resource,
+// e.g. a restricted key without permissions for a collection
+func (e *StripeError) IsPermissionRelated() bool {
+	return e.StatusCode == http.StatusForbidden
 }
 
-func (e *permanentError) IsPermanent() bool {
-	return true
+func (e *StripeError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("unexpected response status code %d", e.StatusCode)
+	}
+	return fmt.Sprintf("unexpected response status code %d: %s", e.StatusCode, e.Message)
+}
+
+// Reason returns a description of the error that can be shown to a customer
+func (e *StripeError) Reason() string {
+	reason := e.Message
+	if reason == "" {
+		reason = fmt.Sprintf("Stripe API responded with %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	if e.RequestId != "" {
+		reason = fmt.Sprintf("%s (request id: %s)", reason, e.RequestId)
+	}
+	return reason
 }
 
-func (e *permanentError) IsAuthRelated() bool {
-	return e.isAuthRelated
+// Fields adds Stripe's error description to the log fields of the wrapping errors
+func (e *StripeError) Fields() log.Fields {
+	return log.Fields{"stripe_error": log.Fields{
+		"type":         e.Type,
+		"code":         e.Code,
+		"decline_code": e.DeclineCode,
+		"param":        e.Param,
+		"request_id":   e.RequestId,
+		"status_code":  e.StatusCode,
+	}}
+}
+
+// newStripeError reads the error description from a response body if there's one
+func newStripeError(resp *http.Response, body []byte) *StripeError {
+	stripeErr := &StripeError{
+		RequestId:  resp.Header.Get("Request-Id"),
+		StatusCode: resp.StatusCode,
+	}
+
+	output := errorResponse{}
+	if decodeErr := json.NewDecoder(bytes.NewReader(body)).Decode(&output); decodeErr == nil && output.Error != nil {
+		stripeErr.Type = output.Error.Type
+		stripeErr.Code = output.Error.Code
+		stripeErr.DeclineCode = output.Error.DeclineCode
+		stripeErr.Param = output.Error.Param
+		stripeErr.Message = output.Error.Message
+	}
+
+	return stripeErr
 }
 
 func getErrorInterface(err error) errorInterface {
@@ -59,8 +128,8 @@ func IsErrorPermanent(err error) bool {
 	return false
 }
 
-// IsErrorAuthRelated returns true if any error in a wrapper chain is a permanent error
-// and has isAuthRelated flag set
+// IsErrorAuthRelated returns true if any error in a wrapper chain was caused
+// by an invalid or revoked API key
 func IsErrorAuthRelated(err error) bool {
 	if i := getErrorInterface(err); i != nil {
 		return i.IsAuthRelated()
@@ -68,3 +137,30 @@ func IsErrorAuthRelated(err error) bool {
 
 	return false
 }
+
+// IsErrorPermissionRelated returns true if any error in a wrapper chain was caused
+// by a lack of permissions of the API key
+func IsErrorPermissionRelated(err error) bool {
+	if i := getErrorInterface(err); i != nil {
+		return i.IsPermissionRelated()
+	}
+
+	return false
+}
+
+// GetStripeError returns the StripeError from a wrapper chain or nil if the chain doesn't have one
+func GetStripeError(err error) *StripeError {
+	for err != nil {
+		if stripeErr, ok := err.(*StripeError); ok {
+			return stripeErr
+		}
+
+		if causer, ok := err.(causer); ok {
+			err = causer.Cause()
+		} else {
+			return nil
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package api
+
+import (
+	"context"
+	"github.com/segmentio/ur-log"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestStripeError(t *testing.T) {
+	a := assert.New(t)
+
+	resp := &http.Response{
+		StatusCode: 403,
+		Header:     http.Header{"Request-Id": []string{"req_8Fw3RHyCMcYn2c"}},
+	}
+	body := []byte(`{"error": {"type": "invalid_request_error", "code": "secret_key_required", "message": "The provided key 'rk_live_***123' does not have the required permissions for this endpoint."}}`)
+	err := urlog.WrapError(context.Background(), newStripeError(resp, body), "")
+	err = urlog.WrapError(context.Background(), err, "failed to fetch object list")
+
+	a.True(IsErrorPermanent(err))
+	a.True(IsErrorPermissionRelated(err))
+	a.False(IsErrorAuthRelated(err))
+
+	stripeErr := GetStripeError(err)
+	if !a.NotNil(stripeErr) {
+		return
+	}
+	a.Equal("invalid_request_error", stripeErr.Type)
+	a.Equal("secret_key_required", stripeErr.Code)
+	a.Equal("The provided key 'rk_live_***123' does not have the required permissions for this endpoint. (request id: req_8Fw3RHyCMcYn2c)", stripeErr.Reason())
+}
+
+func TestStripeErrorWithoutBody(t *testing.T) {
+	a := assert.New(t)
+
+	resp := &http.Response{StatusCode: 503, Header: http.Header{}}
+	err := newStripeError(resp, []byte("<html></html>"))
+
+	a.False(IsErrorPermanent(err))
+	a.Equal("Stripe API responded with 503 Service Unavailable", err.Reason())
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"fmt"
+	"github.com/nu7hatch/gouuid"
+	"net/http"
+	"net/url"
+)
+
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// EncodeParams converts nested params to form values using Stripe's bracket syntax,
+// e.g. {"parameters": {"interval_start": 1}} becomes parameters[interval_start]=1
+// and {"expand": ["customer"]} becomes expand[0]=customer
+func EncodeParams(params map[string]interface{}) url.Values {
+	values := url.Values{}
+	for key, value := range params {
+		encodeParam(values, key, value)
+	}
+	return values
+}
+
+func encodeParam(values url.Values, key string, value interface{}) {
+	switch v := value.(type) {
+	case nil:
+		values.Add(key, "")
+	case map[string]interface{}:
+		for innerKey, innerValue := range v {
+			encodeParam(values, fmt.Sprintf("%s[%s]", key, innerKey), innerValue)
+		}
+	case Object:
+		encodeParam(values, key, map[string]interface{}(v))
+	case []interface{}:
+		for i, item := range v {
+			encodeParam(values, fmt.Sprintf("%s[%d]", key, i), item)
+		}
+	case []string:
+		for i, item := range v {
+			values.Add(fmt.Sprintf("%s[%d]", key, i), item)
+		}
+	default:
+		values.Add(key, fmt.Sprint(v))
+	}
+}
+
+// WithIdempotencyKey returns a copy of the request with a newly generated Idempotency-Key header
+// unless the request already has one. Retrying the returned request can't apply a POST twice.
+func WithIdempotencyKey(req *Request) *Request {
+	if req.Headers.Get(idempotencyKeyHeader) != "" {
+		return req
+	}
+
+	uv4, err := uuid.NewV4()
+	if err != nil {
+		// the client will generate a key for every attempt instead
+		return req
+	}
+
+	newReq := *req
+	newReq.Headers = http.Header{}
+	for key, value := range req.Headers {
+		newReq.Headers[key] = value
+	}
+	newReq.Headers.Set(idempotencyKeyHeader, uv4.String())
+
+	return &newReq
+}
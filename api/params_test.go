@@ -0,0 +1,38 @@
+package api
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/url"
+	"testing"
+)
+
+func TestEncodeParams(t *testing.T) {
+	values := EncodeParams(map[string]interface{}{
+		"report_type": "balance.summary.1",
+		"parameters": map[string]interface{}{
+			"interval_start": 1501174932,
+			"columns":        []string{"category", "net"},
+		},
+		"expand": []interface{}{"data.customer"},
+	})
+
+	assert.Equal(t, url.Values{
+		"report_type":                []string{"balance.summary.1"},
+		"parameters[interval_start]": []string{"1501174932"},
+		"parameters[columns][0]":     []string{"category"},
+		"parameters[columns][1]":     []string{"net"},
+		"expand[0]":                  []string{"data.customer"},
+	}, values)
+}
+
+func TestWithIdempotencyKey(t *testing.T) {
+	a := assert.New(t)
+
+	req := &Request{Url: "/v1/reporting/report_runs"}
+	withKey := WithIdempotencyKey(req)
+	a.Empty(req.Headers.Get(idempotencyKeyHeader))
+	a.NotEmpty(withKey.Headers.Get(idempotencyKeyHeader))
+
+	// a request that has a key already is left intact so that every retry reuses it
+	a.Equal(withKey, WithIdempotencyKey(withKey))
+}
@@ -0,0 +1,88 @@
+package api
+
+import "sync/atomic"
+
+// Quota limits the number of requests made during a run, e.g. when a customer caps our API usage by contract
+type Quota struct {
+	limit int64
+	used  int64
+}
+
+// QuotaExceededError is returned instead of performing a request once the quota is used up.
+// It's permanent, so the request isn't retried.
+type QuotaExceededError struct {
+	Limit int64
+}
+
+func (e *QuotaExceededError) Error() string {
+	return "request quota of the run is exhausted"
+}
+
+func (e *QuotaExceededError) IsPermanent() bool {
+	return true
+}
+
+func (e *QuotaExceededError) IsAuthRelated() bool {
+	return false
+}
+
+func (e *QuotaExceededError) IsPermissionRelated() bool {
+	return false
+}
+
+// use counts a request, a nil quota is unlimited
+func (q *Quota) use() error {
+	if q == nil {
+		return nil
+	}
+
+	if atomic.AddInt64(&q.used, 1) > q.limit {
+		return &QuotaExceededError{Limit: q.limit}
+	}
+
+	return nil
+}
+
+// Exhausted returns true if a request was refused because of the quota
+func (q *Quota) Exhausted() bool {
+	return q != nil && atomic.LoadInt64(&q.used) > q.limit
+}
+
+// Used returns the number of requests that were performed
+func (q *Quota) Used() int64 {
+	if q == nil {
+		return 0
+	}
+
+	used := atomic.LoadInt64(&q.used)
+	if used > q.limit {
+		return q.limit
+	}
+	return used
+}
+
+// IsErrorQuotaExceeded returns true if any error in a wrapper chain was caused by an exhausted quota
+func IsErrorQuotaExceeded(err error) bool {
+	for err != nil {
+		if _, ok := err.(*QuotaExceededError); ok {
+			return true
+		}
+
+		if causer, ok := err.(causer); ok {
+			err = causer.Cause()
+		} else {
+			return false
+		}
+	}
+
+	return false
+}
+
+// NewQuota returns nil if the number of requests isn't limited
+func NewQuota(limit int) *Quota {
+	if limit < 1 {
+		return nil
+	}
+
+	return &Quota{limit: int64(limit)}
+}
@@ -0,0 +1,33 @@
+package api
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestQuota(t *testing.T) {
+	c, _, _ := newStreamingClient(&bodyClient{status: 200, body: listBody}, false)
+	c.quota = NewQuota(1)
+
+	_, err := c.GetList(context.Background(), &Request{Url: "/v1/customers"})
+
+	a := assert.New(t)
+	a.NoError(err)
+	a.False(c.quota.Exhausted())
+
+	_, err = c.GetList(context.Background(), &Request{Url: "/v1/customers"})
+	a.True(IsErrorQuotaExceeded(err))
+	a.True(IsErrorPermanent(err))
+	a.True(c.quota.Exhausted())
+	a.Equal(int64(1), c.quota.Used())
+}
+
+func TestUnlimitedQuota(t *testing.T) {
+	var quota *Quota = NewQuota(0)
+
+	a := assert.New(t)
+	a.Nil(quota)
+	a.NoError(quota.use())
+	a.False(quota.Exhausted())
+}
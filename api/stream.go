@@ -0,0 +1,99 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// countingReader counts the bytes of a response body that was read, so that the payload size is known
+// without keeping the body
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count += int64(n)
+	return n, err
+}
+
+// decodeList reads a list or search result object and passes the elements of its data array to fn
+// one at a time, other fields than has_more and next_page are skipped
+func decodeList(reader io.Reader, fn func(Object) error) (*ObjectList, error) {
+	decoder := json.NewDecoder(reader)
+	decoder.UseNumber()
+
+	if err := expectDelim(decoder, '{'); err != nil {
+		return nil, err
+	}
+
+	result := &ObjectList{}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch token {
+		case "data":
+			if err := decodeData(decoder, fn); err != nil {
+				return nil, err
+			}
+		case "has_more":
+			if err := decoder.Decode(&result.HasMore); err != nil {
+				return nil, err
+			}
+		case "next_page":
+			var nextPage *string
+			if err := decoder.Decode(&nextPage); err != nil {
+				return nil, err
+			}
+			if nextPage != nil {
+				result.NextPage = *nextPage
+			}
+		default:
+			var skipped json.RawMessage
+			if err := decoder.Decode(&skipped); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := expectDelim(decoder, '}'); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func decodeData(decoder *json.Decoder, fn func(Object) error) error {
+	if err := expectDelim(decoder, '['); err != nil {
+		return err
+	}
+
+	for decoder.More() {
+		obj := Object{}
+		if err := decoder.Decode(&obj); err != nil {
+			return err
+		}
+		if err := fn(obj); err != nil {
+			return err
+		}
+	}
+
+	return expectDelim(decoder, ']')
+}
+
+func expectDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("expected %s, got %v", delim, token)
+	}
+
+	return nil
+}
@@ -0,0 +1,172 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/segmentio/go-source"
+	"github.com/segmentio/go-source/source-logger"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+type statsClient struct {
+	source.Client
+	histograms map[string]int64
+}
+
+func (c *statsClient) StatsIncrement(name string, value int64, tags []string) error {
+	return nil
+}
+
+func (c *statsClient) StatsHistogram(name string, value int64, tags []string) error {
+	c.histograms[name] = value
+	return nil
+}
+
+type payloadLogger struct {
+	payloads []interface{}
+}
+
+func (l *payloadLogger) RequestSent(collection string, query string, metadata sourcelogger.Metadata) {
+}
+
+func (l *payloadLogger) ResponseReceived(collection string, query string, metadata sourcelogger.Metadata, latency time.Duration, payload interface{}) {
+	l.payloads = append(l.payloads, payload)
+}
+
+type bodyClient struct {
+	status int
+	body   string
+}
+
+func (c *bodyClient) Do(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: c.status,
+		Status:     http.StatusText(c.status),
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(strings.NewReader(c.body)),
+	}, nil
+}
+
+const listBody = `{
+	"object": "list",
+	"url": "/v1/customers",
+	"data": [
+		{"id": "cus_2", "object": "customer", "balance": 100, "metadata": {"data": [1, 2]}},
+		{"id": "cus_1", "object": "customer", "balance": 0, "metadata": {}}
+	],
+	"has_more": true,
+	"next_page": null
+}`
+
+func newStreamingClient(httpClient HttpClient, logListPayloads bool) (*clientImpl, *statsClient, *payloadLogger) {
+	stats := &statsClient{histograms: map[string]int64{}}
+	logger := &payloadLogger{}
+	return &clientImpl{
+		httpClient:      httpClient,
+		baseUrl:         "https://api.stripe.com",
+		throttler:       NewThrottler(100, time.Second),
+		sourceClient:    stats,
+		sourceLogger:    logger,
+		adapter:         NewVersionAdapter("2019-10-17"),
+		logListPayloads: logListPayloads,
+	}, stats, logger
+}
+
+func TestStreamList(t *testing.T) {
+	c, stats, logger := newStreamingClient(&bodyClient{status: 200, body: listBody}, false)
+
+	objects := []Object{}
+	res, err := c.StreamList(context.Background(), &Request{Url: "/v1/customers"}, func(obj Object) error {
+		objects = append(objects, obj)
+		return nil
+	})
+
+	a := assert.New(t)
+	if !a.NoError(err) {
+		return
+	}
+	a.True(res.HasMore)
+	a.Equal("", res.NextPage)
+	a.Empty(res.Objects)
+	a.Equal([]Object{
+		{
+			"id":              "cus_2",
+			"object":          "customer",
+			"balance":         json.Number("100"),
+			"account_balance": json.Number("100"),
+			"metadata":        map[string]interface{}{"data": []interface{}{json.Number("1"), json.Number("2")}},
+		},
+		{
+			"id":              "cus_1",
+			"object":          "customer",
+			"balance":         json.Number("0"),
+			"account_balance": json.Number("0"),
+			"metadata":        map[string]interface{}{},
+		},
+	}, objects)
+	a.Equal(int64(len(listBody)), stats.histograms["stripe.response.payload_size"])
+	a.Equal([]interface{}{nil}, logger.payloads)
+}
+
+func TestStreamListLogsPayloads(t *testing.T) {
+	c, _, logger := newStreamingClient(&bodyClient{status: 200, body: listBody}, true)
+
+	res, err := c.GetList(context.Background(), &Request{Url: "/v1/customers"})
+
+	a := assert.New(t)
+	if !a.NoError(err) {
+		return
+	}
+	a.Len(res.Objects, 2)
+	a.Equal([]interface{}{listBody}, logger.payloads)
+}
+
+func TestStreamListRetainsPayloadsForDebugLogging(t *testing.T) {
+	c, _, logger := newStreamingClient(&bodyClient{status: 200, body: listBody}, false)
+	c.debugLogging = true
+
+	_, err := c.GetList(context.Background(), &Request{Url: "/v1/customers"})
+
+	a := assert.New(t)
+	if !a.NoError(err) {
+		return
+	}
+	a.Equal([]interface{}{listBody}, logger.payloads)
+}
+
+func TestStreamListStopsOnCallbackError(t *testing.T) {
+	c, _, _ := newStreamingClient(&bodyClient{status: 200, body: listBody}, false)
+	failure := errors.New("processor failed")
+
+	calls := 0
+	_, err := c.StreamList(context.Background(), &Request{Url: "/v1/customers"}, func(obj Object) error {
+		calls++
+		return failure
+	})
+
+	assert.Equal(t, failure, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestStreamListError(t *testing.T) {
+	c, _, logger := newStreamingClient(&bodyClient{
+		status: 429,
+		body:   `{"error": {"type": "invalid_request_error", "message": "Too many requests"}}`,
+	}, false)
+
+	_, err := c.StreamList(context.Background(), &Request{Url: "/v1/customers"}, func(obj Object) error {
+		return nil
+	})
+
+	a := assert.New(t)
+	a.Error(err)
+	a.False(IsErrorPermanent(err))
+	a.Len(logger.payloads, 1)
+	a.NotNil(logger.payloads[0])
+}
@@ -1,29 +1,143 @@
 package api
 
-import "time"
+import (
+	"context"
+	"sync"
+	"time"
+)
 
 // Throttler can be used to apply client-side throttling
 type Throttler struct {
 	eventsPerDuration int
 	duration          time.Duration
 	interval          time.Duration
-	queue             chan struct{}
+	mu                sync.Mutex
+	tokens            int
+	shares            map[string]*share
+	// virtual is the pass of the share that was granted the last event
+	virtual float64
+}
+
+// share is a group of events that gets a part of the rate proportional to its weight when events
+// of multiple shares are waiting, it's scheduled by the smallest pass which grows with every granted event
+type share struct {
+	weight  int
+	pass    float64
+	waiters []chan struct{}
+	// interval between events of a share with a budget, events aren't granted before nextEvent
+	interval  time.Duration
+	nextEvent time.Time
+}
+
+func (s *share) ready(now time.Time) bool {
+	return !now.Before(s.nextEvent)
+}
+
+func (s *share) granted(now time.Time) {
+	if s.interval > 0 {
+		s.nextEvent = now.Add(s.interval)
+	}
+}
+
+type shareKey struct{}
+
+type shareValue struct {
+	name   string
+	weight int
+	rps    int
+}
+
+// WithShare returns a context whose requests are throttled as a part of the named share,
+// e.g. requests of a single collection's producer. If rps is positive, it's the share's budget
+// which can't be exceeded even if the rest of the rate isn't used.
+func WithShare(ctx context.Context, name string, weight int, rps int) context.Context {
+	return context.WithValue(ctx, shareKey{}, shareValue{name: name, weight: weight, rps: rps})
+}
+
+func shareFromContext(ctx context.Context) shareValue {
+	if value, ok := ctx.Value(shareKey{}).(shareValue); ok {
+		return value
+	}
+
+	return shareValue{weight: 1}
 }
 
 func (t *Throttler) run() {
 	interval := time.Duration(float64(t.duration) / float64(t.eventsPerDuration))
 	for {
-		select {
-		case t.queue <- struct{}{}:
-		default:
-		}
+		t.release()
 		time.Sleep(interval)
 	}
 }
 
+// release grants an event to the first waiter of the share with the smallest pass, shares that used up
+// their budget are skipped. If nobody is waiting, the event is kept for later, up to eventsPerDuration events.
+func (t *Throttler) release() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	var next *share
+	for _, s := range t.shares {
+		if len(s.waiters) > 0 && s.ready(now) && (next == nil || s.pass < next.pass) {
+			next = s
+		}
+	}
+
+	if next == nil {
+		if t.tokens < t.eventsPerDuration {
+			t.tokens++
+		}
+		return
+	}
+
+	t.virtual = next.pass
+	next.pass += 1 / float64(next.weight)
+	next.granted(now)
+	close(next.waiters[0])
+	next.waiters = next.waiters[1:]
+}
+
 // Use will block until the next event is allowed to start
 func (t *Throttler) Use() {
-	<-t.queue
+	t.UseShare("", 1, 0)
+}
+
+// UseShare will block until the next event of the share is allowed to start
+func (t *Throttler) UseShare(name string, weight int, rps int) {
+	t.mu.Lock()
+	s, ok := t.shares[name]
+	if !ok {
+		s = &share{}
+		t.shares[name] = s
+	}
+	if weight < 1 {
+		weight = 1
+	}
+	s.weight = weight
+	s.interval = 0
+	if rps > 0 {
+		s.interval = time.Duration(float64(time.Second) / float64(rps))
+	}
+
+	now := time.Now()
+	if t.tokens > 0 && len(s.waiters) == 0 && s.ready(now) {
+		t.tokens--
+		s.granted(now)
+		t.mu.Unlock()
+		return
+	}
+
+	// a share that was idle doesn't get credit for the time it wasn't waiting
+	if len(s.waiters) == 0 && s.pass < t.virtual {
+		s.pass = t.virtual
+	}
+
+	ready := make(chan struct{})
+	s.waiters = append(s.waiters, ready)
+	t.mu.Unlock()
+
+	<-ready
 }
 
 // NewThrottler(50, time.Second) will return a throttler allowing no more than 50 events start
@@ -32,7 +146,7 @@ func NewThrottler(eventsPerDuration int, duration time.Duration) *Throttler {
 	t := Throttler{
 		eventsPerDuration: eventsPerDuration,
 		duration:          duration,
-		queue:             make(chan struct{}, eventsPerDuration),
+		shares:            map[string]*share{},
 	}
 	go t.run()
 	return &t
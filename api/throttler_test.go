@@ -0,0 +1,76 @@
+package api
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func waitingCount(t *Throttler, name string) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if s, ok := t.shares[name]; ok {
+		return len(s.waiters)
+	}
+	return 0
+}
+
+func TestThrottlerShares(t *testing.T) {
+	// events are only released by the test
+	throttler := &Throttler{eventsPerDuration: 1, shares: map[string]*share{}}
+	for i := 0; i < 30; i++ {
+		go throttler.UseShare("plans", 3, 0)
+		go throttler.UseShare("charges", 1, 0)
+	}
+	for waitingCount(throttler, "plans") < 30 || waitingCount(throttler, "charges") < 30 {
+		time.Sleep(time.Millisecond)
+	}
+
+	for i := 0; i < 20; i++ {
+		throttler.release()
+	}
+
+	a := assert.New(t)
+	a.Equal(15, 30-waitingCount(throttler, "plans"))
+	a.Equal(5, 30-waitingCount(throttler, "charges"))
+}
+
+func TestThrottlerKeepsUnusedEvents(t *testing.T) {
+	throttler := &Throttler{eventsPerDuration: 2, shares: map[string]*share{}}
+	for i := 0; i < 3; i++ {
+		throttler.release()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		throttler.Use()
+		throttler.Use()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("events released while nobody was waiting weren't kept")
+	}
+	assert.Equal(t, 0, throttler.tokens)
+}
+
+func TestThrottlerBudget(t *testing.T) {
+	throttler := &Throttler{eventsPerDuration: 10, shares: map[string]*share{}}
+	for i := 0; i < 5; i++ {
+		go throttler.UseShare("charges", 1, 1)
+	}
+	for waitingCount(throttler, "charges") < 5 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// only a single event per second is granted to the share, the rest is kept for others
+	for i := 0; i < 5; i++ {
+		throttler.release()
+	}
+
+	a := assert.New(t)
+	a.Equal(4, waitingCount(throttler, "charges"))
+	a.Equal(4, throttler.tokens)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/segmentio/go-source"
 	"github.com/segmentio/go-source/source-logger"
+	"io"
 	"net/http"
 	"net/url"
 	"time"
@@ -12,8 +13,13 @@ import (
 type Object map[string]interface{}
 
 type Request struct {
-	Url           string
-	Qs            url.Values
+	Url string
+	Qs  url.Values
+	// Params are encoded using Stripe's bracket syntax, they're sent in the body of POST requests
+	// and in the query string otherwise
+	Params map[string]interface{}
+	// Expand lists the fields that Stripe should return as objects rather than ids, e.g. "data.customer"
+	Expand        []string
 	Headers       http.Header
 	LogCollection string
 }
@@ -21,21 +27,30 @@ type Request struct {
 type ObjectList struct {
 	Objects []Object
 	HasMore bool
+	// NextPage is the cursor of search results, other lists are paginated using the id of the last object
+	NextPage string
 }
 
 type Client interface {
 	GetList(context.Context, *Request) (*ObjectList, error)
 	GetObject(context.Context, *Request) (Object, error)
+	Post(context.Context, *Request) (Object, error)
+	Delete(context.Context, *Request) (Object, error)
+	StreamFile(ctx context.Context, req *Request, fn func(io.Reader) error) error
+}
+
+// ListStreamer is implemented by clients that can pass objects of a list to a callback while the response
+// is being read, instead of decoding the whole page first
+type ListStreamer interface {
+	StreamList(ctx context.Context, req *Request, fn func(Object) error) (*ObjectList, error)
 }
 
 type HttpClient interface {
 	Do(*http.Request) (*http.Response, error)
 }
 
-type listResponse struct {
-	Object  string   `json:"object"`
-	Data    []Object `json:"data"`
-	HasMore bool     `json:"has_more"`
+type errorResponse struct {
+	Error *errorDescription `json:"error"`
 }
 
 type ClientOptions struct {
@@ -44,6 +59,15 @@ type ClientOptions struct {
 	HttpClient   HttpClient
 	MaxRps       int
 	SourceClient source.Client
+	// ApiVersion is sent as Stripe-Version, objects of newer versions are normalized to DefaultApiVersion
+	ApiVersion string
+	// LogListPayloads sends bodies of list responses to the source logger, they're only kept in memory
+	// if they're logged
+	LogListPayloads bool
+	// DebugLogging adds bodies of list responses to the debug log, it should be set if the log level is debug
+	DebugLogging bool
+	// Quota limits the number of requests during the run, requests fail with QuotaExceededError once it's used up
+	Quota *Quota
 }
 
 type SourceLogger interface {
@@ -0,0 +1,181 @@
+package api
+
+// DefaultApiVersion is the version that every resource's transform was written for
+const DefaultApiVersion = "2016-07-06"
+
+// shim converts objects of a single type from the shape of a newer API version
+// to the shape of DefaultApiVersion, new fields are left untouched
+type shim struct {
+	since      string
+	objectType string
+	apply      func(obj map[string]interface{})
+}
+
+// shims are sorted by version so that changes are undone starting from the oldest one
+var shims = []shim{
+	{"2018-11-08", "invoice", restoreInvoiceClosed},
+	{"2019-10-17", "customer", restoreAccountBalance},
+	{"2020-08-27", "payment_method", paymentMethodToCard},
+	{"2020-08-27", "subscription_item", restorePlan},
+	{"2020-08-27", "line_item", restorePlan},
+	{"2020-08-27", "subscription", restoreSubscriptionPlan},
+}
+
+// VersionAdapter normalizes objects returned by a configured API version,
+// Stripe-Version dates can be compared as strings
+type VersionAdapter struct {
+	version string
+	shims   map[string][]shim
+}
+
+func (a *VersionAdapter) Version() string {
+	return a.version
+}
+
+// Normalize applies shims to the object and all of the objects nested in it. Nested objects are normalized
+// first so that shims of a parent (e.g. a subscription) can rely on its children (e.g. its items).
+func (a *VersionAdapter) Normalize(obj Object) {
+	if len(a.shims) > 0 {
+		a.normalizeValue(map[string]interface{}(obj))
+	}
+}
+
+func (a *VersionAdapter) normalizeValue(value interface{}) {
+	switch v := value.(type) {
+	case []interface{}:
+		for _, item := range v {
+			a.normalizeValue(item)
+		}
+	case map[string]interface{}:
+		for _, item := range v {
+			a.normalizeValue(item)
+		}
+		if objectType, ok := v["object"].(string); ok {
+			for _, s := range a.shims[objectType] {
+				s.apply(v)
+			}
+		}
+	}
+}
+
+func NewVersionAdapter(version string) *VersionAdapter {
+	if version == "" {
+		version = DefaultApiVersion
+	}
+
+	a := &VersionAdapter{
+		version: version,
+		shims:   map[string][]shim{},
+	}
+	for _, s := range shims {
+		if s.since <= version {
+			a.shims[s.objectType] = append(a.shims[s.objectType], s)
+		}
+	}
+	return a
+}
+
+// restoreInvoiceClosed sets closed and forgiven, which were replaced by status and auto_advance
+func restoreInvoiceClosed(obj map[string]interface{}) {
+	if _, ok := obj["closed"]; !ok {
+		if autoAdvance, ok := obj["auto_advance"].(bool); ok {
+			obj["closed"] = !autoAdvance
+		}
+	}
+	if _, ok := obj["forgiven"]; !ok {
+		if status, ok := obj["status"].(string); ok {
+			obj["forgiven"] = status == "uncollectible"
+		}
+	}
+}
+
+// restoreAccountBalance sets account_balance, which was renamed to balance
+func restoreAccountBalance(obj map[string]interface{}) {
+	if _, ok := obj["account_balance"]; !ok {
+		if balance, ok := obj["balance"]; ok {
+			obj["account_balance"] = balance
+		}
+	}
+}
+
+// paymentMethodToCard converts a card payment method to a card source. Newer versions don't embed sources
+// in customers, so their cards are listed as payment methods instead.
+func paymentMethodToCard(obj map[string]interface{}) {
+	card, ok := obj["card"].(map[string]interface{})
+	if !ok || obj["type"] != "card" {
+		return
+	}
+
+	obj["object"] = "card"
+	for _, key := range []string{"brand", "country", "exp_month", "exp_year", "fingerprint", "funding", "last4"} {
+		obj[key] = card[key]
+	}
+	if checks, ok := card["checks"].(map[string]interface{}); ok {
+		obj["address_line1_check"] = checks["address_line1_check"]
+		obj["address_zip_check"] = checks["address_postal_code_check"]
+		obj["cvc_check"] = checks["cvc_check"]
+	}
+	if wallet, ok := card["wallet"].(map[string]interface{}); ok {
+		obj["tokenization_method"] = wallet["type"]
+	}
+
+	if details, ok := obj["billing_details"].(map[string]interface{}); ok {
+		obj["name"] = details["name"]
+		if address, ok := details["address"].(map[string]interface{}); ok {
+			obj["address_city"] = address["city"]
+			obj["address_country"] = address["country"]
+			obj["address_line1"] = address["line1"]
+			obj["address_line2"] = address["line2"]
+			obj["address_state"] = address["state"]
+			obj["address_zip"] = address["postal_code"]
+		}
+	}
+}
+
+// restorePlan sets the plan of subscription items and invoice lines from their price, which replaced it
+func restorePlan(obj map[string]interface{}) {
+	if _, ok := obj["plan"].(map[string]interface{}); ok {
+		return
+	}
+	price, ok := obj["price"].(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	plan := map[string]interface{}{
+		"id":       price["id"],
+		"object":   "plan",
+		"amount":   price["unit_amount"],
+		"created":  price["created"],
+		"currency": price["currency"],
+		"livemode": price["livemode"],
+		"metadata": price["metadata"],
+		"nickname": price["nickname"],
+		"product":  price["product"],
+	}
+	if recurring, ok := price["recurring"].(map[string]interface{}); ok {
+		plan["interval"] = recurring["interval"]
+		plan["interval_count"] = recurring["interval_count"]
+		plan["trial_period_days"] = recurring["trial_period_days"]
+	}
+	obj["plan"] = plan
+}
+
+// restoreSubscriptionPlan sets the plan of single item subscriptions, which was removed in favor of items
+func restoreSubscriptionPlan(obj map[string]interface{}) {
+	if _, ok := obj["plan"]; ok {
+		return
+	}
+	items, ok := obj["items"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	if data, ok := items["data"].([]interface{}); ok && len(data) == 1 {
+		if item, ok := data[0].(map[string]interface{}); ok {
+			obj["plan"] = item["plan"]
+			if _, ok := obj["quantity"]; !ok {
+				obj["quantity"] = item["quantity"]
+			}
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestVersionAdapterDefaultVersion(t *testing.T) {
+	a := NewVersionAdapter("")
+	obj := Object{
+		"id":      "cus_1",
+		"object":  "customer",
+		"balance": 100,
+	}
+	a.Normalize(obj)
+
+	assert.Equal(t, DefaultApiVersion, a.Version())
+	assert.Equal(t, Object{
+		"id":      "cus_1",
+		"object":  "customer",
+		"balance": 100,
+	}, obj)
+}
+
+func TestVersionAdapterNestedObjects(t *testing.T) {
+	obj := Object{
+		"id":     "evt_1",
+		"object": "event",
+		"data": map[string]interface{}{
+			"object": map[string]interface{}{
+				"id":           "in_1",
+				"object":       "invoice",
+				"auto_advance": false,
+				"status":       "uncollectible",
+			},
+		},
+	}
+	NewVersionAdapter("2018-11-08").Normalize(obj)
+
+	assert.Equal(t, Object{
+		"id":     "evt_1",
+		"object": "event",
+		"data": map[string]interface{}{
+			"object": map[string]interface{}{
+				"id":           "in_1",
+				"object":       "invoice",
+				"auto_advance": false,
+				"status":       "uncollectible",
+				"closed":       true,
+				"forgiven":     true,
+			},
+		},
+	}, obj)
+}
+
+func TestVersionAdapterOnlyAppliesNewerShims(t *testing.T) {
+	obj := Object{
+		"id":      "cus_1",
+		"object":  "customer",
+		"balance": 100,
+		"sources": map[string]interface{}{
+			"object": "list",
+			"data": []interface{}{
+				map[string]interface{}{
+					"id":     "pm_1",
+					"object": "payment_method",
+					"type":   "card",
+					"card":   map[string]interface{}{"brand": "Visa"},
+				},
+			},
+		},
+	}
+	NewVersionAdapter("2019-12-03").Normalize(obj)
+
+	a := assert.New(t)
+	a.Equal(100, obj["account_balance"])
+	a.Equal("payment_method", obj["sources"].(map[string]interface{})["data"].([]interface{})[0].(map[string]interface{})["object"])
+}
@@ -0,0 +1,70 @@
+package integration
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Backfill downloads objects of selected collections created in a time range again, alongside an incremental
+// run, e.g. after a transform was fixed. It doesn't change the timestamp that the next run continues from.
+type Backfill struct {
+	Collections map[string]bool
+	From        time.Time
+	// To isn't included, if it's zero the range isn't bounded
+	To time.Time
+
+	mu      sync.Mutex
+	started map[string]bool
+}
+
+// BackfillReport records a backfill in the saved context
+type BackfillReport struct {
+	Collections []string  `json:"collections"`
+	From        time.Time `json:"from"`
+	To          time.Time `json:"to,omitempty"`
+	RunAt       time.Time `json:"run_at"`
+	// Skipped collections weren't synced or their list endpoints don't support created filters
+	Skipped   []string `json:"skipped,omitempty"`
+	Completed bool     `json:"completed"`
+}
+
+// maxBackfillReports is the number of the most recent backfills kept in the saved context
+const maxBackfillReports = 10
+
+// Start returns true if the collection should be backfilled and records that it was
+func (b *Backfill) Start(collection string) bool {
+	if b == nil || !b.Collections[collection] {
+		return false
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.started == nil {
+		b.started = map[string]bool{}
+	}
+	b.started[collection] = true
+	return true
+}
+
+func (b *Backfill) report(runAt time.Time, completed bool) BackfillReport {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	report := BackfillReport{
+		From:      b.From,
+		To:        b.To,
+		RunAt:     runAt,
+		Completed: completed,
+	}
+	for collection := range b.Collections {
+		if b.started[collection] {
+			report.Collections = append(report.Collections, collection)
+		} else {
+			report.Skipped = append(report.Skipped, collection)
+		}
+	}
+	sort.Strings(report.Collections)
+	sort.Strings(report.Skipped)
+	return report
+}
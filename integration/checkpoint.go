@@ -0,0 +1,107 @@
+package integration
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Checkpoint records the progress of a run's listings, it's saved if the request quota is exhausted so that the
+// next run continues the sync instead of starting over. Listings that finished aren't downloaded again and
+// the others continue from their cursors, a listing is identified by its collection and first request.
+type Checkpoint struct {
+	// StartedAt is the start of the first run of the sync, the next sync continues from it once this one finishes
+	StartedAt time.Time `json:"started_at"`
+	// EventsSince is the creation time of the oldest event processed before an events listing was stopped.
+	// The rest of such a listing is older than events processed already and may overwrite newer versions
+	// of objects, so the next sync lists events since then again.
+	EventsSince time.Time                `json:"events_since,omitempty"`
+	Lists       map[string]*ListProgress `json:"lists,omitempty"`
+
+	mu sync.Mutex
+}
+
+// ListProgress is the progress of a listing, only one of its cursors is set
+type ListProgress struct {
+	Done bool `json:"done,omitempty"`
+	// StartingAfter is the id of the last listed object
+	StartingAfter string `json:"starting_after,omitempty"`
+	// Page is the token of the next page of a search
+	Page string `json:"page,omitempty"`
+	// Windows are the unfinished windows of a listing that is split into windows of the creation time
+	Windows []ListWindow `json:"windows,omitempty"`
+	// EventsSince is the creation time of the last listed event, if the listing lists events
+	EventsSince int64 `json:"events_since,omitempty"`
+}
+
+// ListWindow covers objects created in [Gte, Lt), StartingAfter is the last listed object
+type ListWindow struct {
+	Gte           int64  `json:"gte"`
+	Lt            int64  `json:"lt"`
+	StartingAfter string `json:"starting_after,omitempty"`
+}
+
+type checkpointKey struct{}
+
+// Progress returns the progress of a listing recorded by a previous run, or false if it wasn't started
+func (c *Checkpoint) Progress(key string) (ListProgress, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	progress, ok := c.Lists[key]
+	if !ok {
+		return ListProgress{}, false
+	}
+	return *progress, true
+}
+
+// SetProgress records the progress of a listing after a page was downloaded
+func (c *Checkpoint) SetProgress(key string, progress ListProgress) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.Lists == nil {
+		c.Lists = map[string]*ListProgress{}
+	}
+	c.Lists[key] = &progress
+}
+
+// Finish records that every page of a listing was downloaded
+func (c *Checkpoint) Finish(key string) {
+	c.SetProgress(key, ListProgress{Done: true})
+}
+
+// stop updates EventsSince with the events listings that haven't finished
+func (c *Checkpoint) stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, progress := range c.Lists {
+		if progress.Done || progress.EventsSince == 0 {
+			continue
+		}
+		since := time.Unix(progress.EventsSince, 0).UTC()
+		if c.EventsSince.IsZero() || since.Before(c.EventsSince) {
+			c.EventsSince = since
+		}
+	}
+}
+
+// since returns the timestamp that the next sync continues from once this one finished
+func (c *Checkpoint) since(incremental bool) time.Time {
+	if incremental && !c.EventsSince.IsZero() && c.EventsSince.Before(c.StartedAt) {
+		return c.EventsSince
+	}
+	return c.StartedAt
+}
+
+// GetCheckpoint returns the checkpoint of the run or nil if the context doesn't have one
+func GetCheckpoint(ctx context.Context) *Checkpoint {
+	c, _ := ctx.Value(checkpointKey{}).(*Checkpoint)
+	return c
+}
+
+// WithCheckpoint returns a context in which listings record their progress in the checkpoint
+func WithCheckpoint(ctx context.Context, c *Checkpoint) context.Context {
+	return context.WithValue(ctx, checkpointKey{}, c)
+}
@@ -25,10 +25,30 @@ type Dispatcher struct {
 	producerFailures    int32
 	collectionErrors    int32
 	runContext          RunContext
+	accountId           string
+	livemode            bool
+	syncedIds           *idSet
+	tombstones          map[string][]string
+	scheduler           *Scheduler
+	quota               Quota
+	backfill            *Backfill
+	backfills           []BackfillReport
+	staleThreshold      time.Duration
+	switchTombstones    bool
+	reconcile           bool
+	previousIds         *idSet
+	seenIds             *idSet
+	syncedIdsLimit      int
+	modeKnown           bool
+	checkpoint          *Checkpoint
+	// resumed is set if the run continues a sync that exhausted the request quota
+	resumed bool
 }
 
 const contextVersion = 1
-const staleContextThreshold = time.Hour * 24 * 30 // 30 days
+
+// defaultStaleContextThreshold is Stripe's events retention, events since an older run can't be downloaded
+const defaultStaleContextThreshold = time.Hour * 24 * 30 // 30 days
 
 func (d *Dispatcher) Register(res Resource) {
 	d.resources = append(d.resources, res)
@@ -68,10 +88,23 @@ func (d *Dispatcher) setWorker(sub subscription) {
 		if err := d.sourceClient.Set(msg.Collection, msg.ID, msg.Properties); err != nil {
 			log.WithError(err).Fatal("Set call failed, aborting the sync")
 		}
+		if d.syncedIds != nil {
+			d.syncedIds.Add(msg.Collection, msg.ID)
+		}
+		// only previously synced objects are checked by reconciliation
+		if d.seenIds != nil && d.previousIds.Has(msg.Collection, msg.ID) {
+			d.seenIds.Add(msg.Collection, msg.ID)
+		}
 	}
 }
 
 func (d *Dispatcher) runProducers(ctx context.Context) *sync.WaitGroup {
+	for _, res := range d.resources {
+		d.scheduler.register(res)
+	}
+	ctx = WithScheduler(ctx, d.scheduler)
+	ctx = WithCheckpoint(ctx, d.checkpoint)
+
 	producerWg := sync.WaitGroup{}
 	for _, res := range d.resources {
 		producerWg.Add(1)
@@ -82,6 +115,8 @@ func (d *Dispatcher) runProducers(ctx context.Context) *sync.WaitGroup {
 		producerWg.Add(1)
 		go func(res Resource) {
 			defer producerWg.Done()
+			ctx, finish := StartScheduled(ctx, res)
+			defer finish()
 			if err := res.StartProducer(ctx, d.runContext); err != nil {
 				atomic.AddInt32(&d.producerFailures, 1)
 				operation := fmt.Sprintf("running producer %s", reflect.TypeOf(res).String())
@@ -122,6 +157,7 @@ func (d *Dispatcher) runConsumers(ctx context.Context) *sync.WaitGroup {
 
 func (d *Dispatcher) initContext(ctx context.Context) error {
 	d.startedAt = time.Now().UTC()
+	d.checkpoint = &Checkpoint{StartedAt: d.startedAt}
 
 	doc, err := d.sourceClient.GetContext(source.GetContextOptions{AllowFailed: false})
 	if err != nil {
@@ -134,7 +170,7 @@ func (d *Dispatcher) initContext(ctx context.Context) error {
 		return nil
 	}
 
-	value := RunContext{}
+	value := savedContext{}
 	if err := json.Unmarshal(doc, &value); err != nil {
 		d.sourceClient.Log().Error("", "decoding context", err)
 		log.WithError(err).WithField("context", string(doc)).Error("failed to unmarshal context")
@@ -149,24 +185,127 @@ func (d *Dispatcher) initContext(ctx context.Context) error {
 		return nil
 	}
 
-	if value.PreviousRunTimestamp.IsZero() {
+	// the first full sync doesn't have a timestamp until it finishes
+	if value.PreviousRunTimestamp.IsZero() && value.Checkpoint == nil {
 		log.Info("run context doesn't contain a timestamp")
 		return nil
 	}
 
-	if time.Now().UTC().Sub(value.PreviousRunTimestamp) > staleContextThreshold {
-		log.Infof("discarding context as it is older than %s", staleContextThreshold.String())
+	// contexts saved before the mode was recorded don't have it
+	mode := struct {
+		Livemode *bool `json:"livemode"`
+	}{}
+	json.Unmarshal(doc, &mode)
+
+	// objects of a different account or mode must not be mixed with the ones synced before, the account
+	// may be unknown if a restricted key isn't allowed to read it but the mode is always known
+	accountSwitched := d.accountId != "" && value.AccountId != "" && value.AccountId != d.accountId
+	modeSwitched := d.modeKnown && mode.Livemode != nil && *mode.Livemode != d.livemode
+	if accountSwitched || modeSwitched {
+		log.WithFields(log.Fields{
+			"account_id":          d.accountId,
+			"livemode":            d.livemode,
+			"previous_account_id": value.AccountId,
+			"previous_livemode":   value.Livemode,
+		}).Warn("discarding context as it belongs to a different account")
+		d.sourceClient.ReportWarning("The API key belongs to a different Stripe account or mode than in the previous sync, "+
+			"all collections will be synced again", "")
+		if d.switchTombstones {
+			if len(value.SyncedIds) < 1 {
+				log.Warn("previously synced objects are unknown and can't be deleted")
+			}
+			d.tombstones = value.SyncedIds
+		}
+		return nil
+	}
+
+	if d.syncedIds != nil {
+		d.syncedIds.AddLists(value.SyncedIds)
+	}
+	if d.previousIds != nil {
+		d.previousIds.AddLists(value.SyncedIds)
+	}
+	d.backfills = value.Backfills
+
+	if value.Checkpoint != nil {
+		log.WithField("started_at", value.Checkpoint.StartedAt).Info("continuing the sync stopped by the request quota")
+		d.checkpoint = value.Checkpoint
+		d.resumed = true
+	}
+
+	if value.PreviousRunTimestamp.IsZero() {
+		return nil
+	}
+
+	if time.Now().UTC().Sub(value.PreviousRunTimestamp) > d.staleThreshold {
+		log.Infof("discarding context as it is older than %s", d.staleThreshold.String())
+		d.runContext = RunContext{StaleRunTimestamp: value.PreviousRunTimestamp}
 		return nil
 	}
 
-	d.runContext = value
+	d.runContext = value.RunContext
 	return nil
 }
 
+// sendTombstones marks every object synced for a previous account as deleted
+func (d *Dispatcher) sendTombstones() {
+	for collection, ids := range d.tombstones {
+		log.WithFields(log.Fields{
+			"collection": collection,
+			"count":      len(ids),
+		}).Info("deleting objects of the previous account")
+		for _, id := range ids {
+			if err := d.sourceClient.Set(collection, id, map[string]interface{}{"is_deleted": true}); err != nil {
+				log.WithError(err).Fatal("Set call failed, aborting the sync")
+			}
+		}
+	}
+}
+
 func (d *Dispatcher) saveContext(ctx context.Context) error {
-	value := RunContext{
-		Version:              contextVersion,
-		PreviousRunTimestamp: d.startedAt,
+	previousRunTimestamp := d.startedAt
+	var checkpoint *Checkpoint
+	if d.quotaExhausted() {
+		// objects changed since the previous run may not have been synced, the next run continues the listings
+		// of this one with the same timestamp
+		previousRunTimestamp = d.runContext.PreviousRunTimestamp
+		if previousRunTimestamp.IsZero() {
+			previousRunTimestamp = d.runContext.StaleRunTimestamp
+		}
+		checkpoint = d.checkpoint
+		checkpoint.stop()
+	} else if d.resumed {
+		// objects changed since the first run of the sync may have been skipped by listings that finished before
+		previousRunTimestamp = d.checkpoint.since(!d.runContext.PreviousRunTimestamp.IsZero())
+	}
+
+	value := savedContext{
+		RunContext: RunContext{
+			Version:              contextVersion,
+			PreviousRunTimestamp: previousRunTimestamp,
+			AccountId:            d.accountId,
+			Livemode:             d.livemode,
+		},
+		Checkpoint: checkpoint,
+	}
+	if d.syncedIds != nil {
+		value.SyncedIds = d.syncedIds.Lists()
+	}
+	value.Backfills = d.backfills
+	if d.runContext.Backfill != nil {
+		completed := d.producerFailures == 0 && d.collectionErrors == 0 && !d.quotaExhausted()
+		report := d.runContext.Backfill.report(d.startedAt, completed)
+		log.WithFields(log.Fields{
+			"collections": report.Collections,
+			"skipped":     report.Skipped,
+			"from":        report.From,
+			"to":          report.To,
+			"completed":   report.Completed,
+		}).Info("backfill finished")
+		value.Backfills = append(value.Backfills, report)
+		if len(value.Backfills) > maxBackfillReports {
+			value.Backfills = value.Backfills[len(value.Backfills)-maxBackfillReports:]
+		}
 	}
 
 	doc, _ := json.Marshal(value)
@@ -178,6 +317,69 @@ func (d *Dispatcher) saveContext(ctx context.Context) error {
 	return nil
 }
 
+// SetAccount sets the Stripe account being synced and whether it's synced in live mode.
+// A context saved for another account or mode is discarded.
+func (d *Dispatcher) SetAccount(accountId string, livemode bool) {
+	d.accountId = accountId
+	d.livemode = livemode
+	d.modeKnown = true
+}
+
+// SetSyncedIdsLimit sets the number of ids of synced objects remembered per collection for tombstones and
+// reconciliation, they're stored in the context. Objects that weren't remembered can't be deleted.
+// It must be called before tombstones or reconciliation are enabled.
+func (d *Dispatcher) SetSyncedIdsLimit(limit int) {
+	d.syncedIdsLimit = limit
+}
+
+// EnableTombstones makes the dispatcher remember the ids of synced objects,
+// so that they can be marked as deleted if the API key is switched to another account
+func (d *Dispatcher) EnableTombstones() {
+	d.switchTombstones = true
+	if d.syncedIds == nil {
+		d.syncedIds = newIdSet(d.syncedIdsLimit)
+	}
+}
+
+// EnableReconciliation makes full syncs mark objects that were synced before but weren't downloaded again
+// as deleted, e.g. objects deleted while the source was paused. The ids of synced objects are remembered,
+// and a stale context results in a full sync of every collection rather than a partial resync.
+func (d *Dispatcher) EnableReconciliation() {
+	d.reconcile = true
+	// objects synced before are unknown until a context is loaded
+	d.previousIds = newIdSet(0)
+	if d.syncedIds == nil {
+		d.syncedIds = newIdSet(d.syncedIdsLimit)
+	}
+}
+
+// SetSchedule overrides priorities of collections' producers and sets their budgets of requests per second
+func (d *Dispatcher) SetSchedule(priorities map[string]int, budgets map[string]int) {
+	d.scheduler = NewScheduler(priorities, budgets)
+}
+
+// SetQuota sets the quota of requests that the API client was created with. If it's exhausted,
+// the run stops without failing and the next run continues its listings from where they stopped.
+func (d *Dispatcher) SetQuota(quota Quota) {
+	d.quota = quota
+}
+
+func (d *Dispatcher) quotaExhausted() bool {
+	return d.quota != nil && d.quota.Exhausted()
+}
+
+// SetBackfill selects collections that download objects created in a time range again during
+// an incremental run, a full sync downloads every object anyway
+func (d *Dispatcher) SetBackfill(backfill *Backfill) {
+	d.backfill = backfill
+}
+
+// SetStaleContextThreshold sets the age of a context after which collections aren't synced incrementally.
+// Collections that can list objects created since the stale run do so, the others are fully synced.
+func (d *Dispatcher) SetStaleContextThreshold(threshold time.Duration) {
+	d.staleThreshold = threshold
+}
+
 func (d *Dispatcher) Run() error {
 	ctx := context.Background()
 
@@ -185,6 +387,29 @@ func (d *Dispatcher) Run() error {
 		return err
 	}
 
+	if d.backfill != nil {
+		if d.runContext.PreviousRunTimestamp.IsZero() {
+			log.Info("skipping backfill as every collection is fully synced")
+		} else {
+			d.runContext.Backfill = d.backfill
+		}
+	}
+
+	if d.reconcile && d.runContext.PreviousRunTimestamp.IsZero() {
+		if !d.runContext.StaleRunTimestamp.IsZero() {
+			log.Info("listing every object to reconcile deletions instead of a partial resync")
+			d.runContext.StaleRunTimestamp = time.Time{}
+		}
+		// listings that finished in a previous run are skipped, their objects aren't seen again
+		if d.resumed {
+			log.Info("skipping reconciliation of deletions as the full sync continues a previous run")
+		} else {
+			d.seenIds = newIdSet(0)
+		}
+	}
+
+	d.sendTombstones()
+
 	consumerWg := d.runConsumers(ctx)
 	producerWg := d.runProducers(ctx)
 
@@ -196,10 +421,19 @@ func (d *Dispatcher) Run() error {
 
 	consumerWg.Wait()
 
+	d.reconcileDeletions()
+
 	if err := d.saveContext(ctx); err != nil {
 		return err
 	}
 
+	// producers stopped by the quota fail, but the run is checkpointed rather than failed
+	if d.quotaExhausted() {
+		log.WithField("requests", d.quota.Used()).Warn("request quota exhausted, the sync will continue in the next run")
+		d.sourceClient.ReportWarning("The request quota of the run was exhausted, the sync will continue in the next run", "")
+		return nil
+	}
+
 	if d.producerFailures > 0 {
 		return errors.New("One or more producers failed")
 	}
@@ -222,5 +456,8 @@ func NewDispatcher(sourceClient source.Client) *Dispatcher {
 		sourceClient:        sourceClient,
 		eventSubscriptions:  make(map[string][]subscription),
 		objectSubscriptions: make(map[string][]subscription),
+		scheduler:           NewScheduler(nil, nil),
+		staleThreshold:      defaultStaleContextThreshold,
+		syncedIdsLimit:      defaultSyncedIdsLimit,
 	}
 }
@@ -0,0 +1,432 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/segment-sources/stripe/api"
+	"github.com/segmentio/go-source"
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type setCall struct {
+	Collection string
+	ID         string
+	Properties map[string]interface{}
+}
+
+// mockSourceClient only implements the methods used when loading and saving a context
+type mockSourceClient struct {
+	source.Client
+	context  []byte
+	warnings []string
+	sets     []setCall
+}
+
+func (c *mockSourceClient) GetContext(options source.GetContextOptions) ([]byte, error) {
+	return c.context, nil
+}
+
+func (c *mockSourceClient) SetContext(context []byte) error {
+	c.context = context
+	return nil
+}
+
+func (c *mockSourceClient) ReportWarning(message string, collection string) error {
+	c.warnings = append(c.warnings, message)
+	return nil
+}
+
+func (c *mockSourceClient) Set(collection string, id string, properties map[string]interface{}) error {
+	c.sets = append(c.sets, setCall{collection, id, properties})
+	return nil
+}
+
+func makeContext(t *testing.T, accountId string, livemode bool) []byte {
+	doc, err := json.Marshal(savedContext{
+		RunContext: RunContext{
+			Version:              contextVersion,
+			PreviousRunTimestamp: time.Now().UTC().Add(-time.Hour),
+			AccountId:            accountId,
+			Livemode:             livemode,
+		},
+		SyncedIds: map[string][]string{
+			"charges": {"ch_1", "ch_2"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestInitContextSameAccount(t *testing.T) {
+	client := &mockSourceClient{context: makeContext(t, "acct_1", true)}
+	d := NewDispatcher(client)
+	d.SetAccount("acct_1", true)
+	d.EnableTombstones()
+
+	a := assert.New(t)
+	a.NoError(d.initContext(context.Background()))
+	a.False(d.runContext.PreviousRunTimestamp.IsZero())
+	a.Empty(client.warnings)
+	a.Equal(map[string][]string{"charges": {"ch_1", "ch_2"}}, d.syncedIds.Lists())
+
+	d.sendTombstones()
+	a.Empty(client.sets)
+}
+
+func TestInitContextAccountSwitch(t *testing.T) {
+	client := &mockSourceClient{context: makeContext(t, "acct_1", true)}
+	d := NewDispatcher(client)
+	d.SetAccount("acct_2", true)
+	d.EnableTombstones()
+
+	a := assert.New(t)
+	a.NoError(d.initContext(context.Background()))
+	a.True(d.runContext.PreviousRunTimestamp.IsZero())
+	a.Len(client.warnings, 1)
+	a.Empty(d.syncedIds.Lists())
+
+	d.sendTombstones()
+	a.Equal([]setCall{
+		{"charges", "ch_1", map[string]interface{}{"is_deleted": true}},
+		{"charges", "ch_2", map[string]interface{}{"is_deleted": true}},
+	}, client.sets)
+}
+
+func TestInitContextModeSwitch(t *testing.T) {
+	client := &mockSourceClient{context: makeContext(t, "acct_1", false)}
+	d := NewDispatcher(client)
+	d.SetAccount("acct_1", true)
+
+	a := assert.New(t)
+	a.NoError(d.initContext(context.Background()))
+	a.True(d.runContext.PreviousRunTimestamp.IsZero())
+	a.Len(client.warnings, 1)
+
+	d.sendTombstones()
+	a.Empty(client.sets)
+}
+
+type exhaustedQuota struct{}
+
+func (q exhaustedQuota) Exhausted() bool {
+	return true
+}
+
+func (q exhaustedQuota) Used() int64 {
+	return 100
+}
+
+func TestSaveContextExhaustedQuota(t *testing.T) {
+	client := &mockSourceClient{context: makeContext(t, "acct_1", true)}
+	d := NewDispatcher(client)
+	d.SetAccount("acct_1", true)
+	d.SetQuota(exhaustedQuota{})
+
+	a := assert.New(t)
+	a.NoError(d.initContext(context.Background()))
+	previousRunTimestamp := d.runContext.PreviousRunTimestamp
+	a.NoError(d.saveContext(context.Background()))
+
+	// the next run continues from the same point
+	saved := savedContext{}
+	a.NoError(json.Unmarshal(client.context, &saved))
+	a.True(saved.PreviousRunTimestamp.Equal(previousRunTimestamp))
+}
+
+func TestSaveContext(t *testing.T) {
+	client := &mockSourceClient{context: makeContext(t, "acct_1", true)}
+	d := NewDispatcher(client)
+	d.SetAccount("acct_1", true)
+	d.SetQuota(api.NewQuota(100))
+
+	a := assert.New(t)
+	a.NoError(d.initContext(context.Background()))
+	previousRunTimestamp := d.runContext.PreviousRunTimestamp
+	a.NoError(d.saveContext(context.Background()))
+
+	saved := savedContext{}
+	a.NoError(json.Unmarshal(client.context, &saved))
+	a.True(saved.PreviousRunTimestamp.After(previousRunTimestamp))
+}
+
+func TestSaveContextBackfill(t *testing.T) {
+	client := &mockSourceClient{context: makeContext(t, "acct_1", true)}
+	d := NewDispatcher(client)
+	d.SetAccount("acct_1", true)
+
+	a := assert.New(t)
+	a.NoError(d.initContext(context.Background()))
+	from := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	d.runContext.Backfill = &Backfill{
+		Collections: map[string]bool{"charges": true, "payment_links": true},
+		From:        from,
+	}
+	a.True(d.runContext.Backfill.Start("charges"))
+	a.False(d.runContext.Backfill.Start("customers"))
+	a.NoError(d.saveContext(context.Background()))
+
+	saved := savedContext{}
+	a.NoError(json.Unmarshal(client.context, &saved))
+	if !a.Len(saved.Backfills, 1) {
+		return
+	}
+	a.Equal([]string{"charges"}, saved.Backfills[0].Collections)
+	a.Equal([]string{"payment_links"}, saved.Backfills[0].Skipped)
+	a.True(saved.Backfills[0].From.Equal(from))
+	a.True(saved.Backfills[0].Completed)
+}
+
+func TestBackfillStartWithoutBackfill(t *testing.T) {
+	var b *Backfill
+	assert.False(t, b.Start("charges"))
+}
+
+func TestInitContextStaleThreshold(t *testing.T) {
+	client := &mockSourceClient{context: makeContext(t, "acct_1", true)}
+	d := NewDispatcher(client)
+	d.SetAccount("acct_1", true)
+	d.SetStaleContextThreshold(time.Minute * 30)
+
+	a := assert.New(t)
+	a.NoError(d.initContext(context.Background()))
+	a.True(d.runContext.PreviousRunTimestamp.IsZero())
+	a.False(d.runContext.StaleRunTimestamp.IsZero())
+}
+
+func TestInitContextModeSwitchWithoutAccount(t *testing.T) {
+	client := &mockSourceClient{context: makeContext(t, "acct_1", false)}
+	d := NewDispatcher(client)
+	d.SetAccount("", true)
+
+	a := assert.New(t)
+	a.NoError(d.initContext(context.Background()))
+	a.True(d.runContext.PreviousRunTimestamp.IsZero())
+	a.Len(client.warnings, 1)
+}
+
+func TestInitContextWithoutMode(t *testing.T) {
+	// contexts saved before the account and mode were recorded
+	client := &mockSourceClient{context: []byte(`{"version": 1, "previous_run_timestamp": "` +
+		time.Now().UTC().Add(-time.Hour).Format(time.RFC3339) + `"}`)}
+	d := NewDispatcher(client)
+	d.SetAccount("acct_1", true)
+
+	a := assert.New(t)
+	a.NoError(d.initContext(context.Background()))
+	a.False(d.runContext.PreviousRunTimestamp.IsZero())
+	a.Empty(client.warnings)
+}
+
+func TestSyncedIdsLimit(t *testing.T) {
+	ids := newIdSet(2)
+	ids.AddLists(map[string][]string{"charges": {"ch_1", "ch_2", "ch_3"}, "refunds": {"re_1"}})
+	ids.Add("charges", "ch_1")
+
+	assert.Equal(t, map[string][]string{"charges": {"ch_1", "ch_2"}, "refunds": {"re_1"}}, ids.Lists())
+}
+
+// pageQuota allows a number of pages to be listed in a run
+type pageQuota struct {
+	limit int64
+	used  int64
+}
+
+func (q *pageQuota) Exhausted() bool {
+	return q.used >= q.limit
+}
+
+func (q *pageQuota) Used() int64 {
+	return q.used
+}
+
+// pagedResource lists pages of charges and records its progress in the checkpoint like the downloader does,
+// its cursor is the index of the next page
+type pagedResource struct {
+	pages [][]string
+	quota *pageQuota
+	objs  chan api.Object
+	msgs  chan source.SetMessage
+	errs  chan CollectionError
+}
+
+func newPagedResource(quota *pageQuota, pages ...[]string) *pagedResource {
+	return &pagedResource{
+		pages: pages,
+		quota: quota,
+		objs:  make(chan api.Object),
+		msgs:  make(chan source.SetMessage),
+		errs:  make(chan CollectionError),
+	}
+}
+
+func (r *pagedResource) StartProducer(ctx context.Context, runContext RunContext) error {
+	defer close(r.objs)
+	defer close(r.errs)
+
+	cp := GetCheckpoint(ctx)
+	progress, _ := cp.Progress("charges")
+	if progress.Done {
+		return nil
+	}
+	page := 0
+	if progress.StartingAfter != "" {
+		page, _ = strconv.Atoi(progress.StartingAfter)
+	}
+	for ; page < len(r.pages); page++ {
+		if r.quota.Exhausted() {
+			return nil
+		}
+		r.quota.used++
+		for _, id := range r.pages[page] {
+			r.objs <- api.Object{"id": id, "object": "charge"}
+		}
+		cp.SetProgress("charges", ListProgress{StartingAfter: strconv.Itoa(page + 1)})
+	}
+	cp.Finish("charges")
+	return nil
+}
+
+func (r *pagedResource) StartConsumer(ctx context.Context, ch <-chan api.Object) {
+	defer close(r.msgs)
+	for obj := range ch {
+		r.msgs <- source.SetMessage{ID: obj["id"].(string), Collection: "charges"}
+	}
+}
+
+func (r *pagedResource) Objects() <-chan api.Object {
+	return r.objs
+}
+
+func (r *pagedResource) CollectionErrors() <-chan CollectionError {
+	return r.errs
+}
+
+func (r *pagedResource) Messages() <-chan source.SetMessage {
+	return r.msgs
+}
+
+func (r *pagedResource) Consumers() []Consumer {
+	return []Consumer{r}
+}
+
+func (r *pagedResource) Collection() string {
+	return "charges"
+}
+
+func (r *pagedResource) DesiredEvents() []string {
+	return nil
+}
+
+func (r *pagedResource) DesiredObjects() []string {
+	return []string{"charge"}
+}
+
+func (r *pagedResource) Close() {}
+
+func TestFullSyncExhaustingQuotaTwice(t *testing.T) {
+	client := &mockSourceClient{}
+	a := assert.New(t)
+
+	runs := []struct {
+		limit         int64
+		startingAfter string
+	}{
+		{limit: 1, startingAfter: "1"},
+		{limit: 1, startingAfter: "2"},
+		{limit: 10},
+	}
+	var startedAt time.Time
+	for i, run := range runs {
+		d := NewDispatcher(client)
+		d.SetAccount("acct_1", true)
+		quota := &pageQuota{limit: run.limit}
+		d.SetQuota(quota)
+		d.Register(newPagedResource(quota, []string{"ch_5", "ch_4"}, []string{"ch_3", "ch_2"}, []string{"ch_1"}))
+		a.NoError(d.Run())
+		if i == 0 {
+			startedAt = d.startedAt
+		}
+
+		saved := savedContext{}
+		a.NoError(json.Unmarshal(client.context, &saved))
+		if run.startingAfter == "" {
+			// the next run continues with events since the first run of the full sync
+			a.Nil(saved.Checkpoint)
+			a.True(saved.PreviousRunTimestamp.Equal(startedAt))
+			continue
+		}
+
+		// a full sync that didn't finish is continued without a timestamp
+		a.True(saved.PreviousRunTimestamp.IsZero())
+		if a.NotNil(saved.Checkpoint) {
+			a.True(saved.Checkpoint.StartedAt.Equal(startedAt))
+			a.Equal(&ListProgress{StartingAfter: run.startingAfter}, saved.Checkpoint.Lists["charges"])
+		}
+	}
+
+	ids := []string{}
+	for _, set := range client.sets {
+		ids = append(ids, set.ID)
+	}
+	a.Equal([]string{"ch_5", "ch_4", "ch_3", "ch_2", "ch_1"}, ids)
+	a.Len(client.warnings, 2)
+}
+
+func TestIncrementalSyncExhaustingQuota(t *testing.T) {
+	client := &mockSourceClient{context: makeContext(t, "acct_1", true)}
+	d := NewDispatcher(client)
+	d.SetAccount("acct_1", true)
+	d.SetQuota(exhaustedQuota{})
+
+	a := assert.New(t)
+	a.NoError(d.initContext(context.Background()))
+	previousRunTimestamp := d.runContext.PreviousRunTimestamp
+	eventsSince := previousRunTimestamp.Add(time.Minute * 10).Truncate(time.Second)
+	d.checkpoint.SetProgress("charges events", ListProgress{StartingAfter: "evt_1", EventsSince: eventsSince.Unix()})
+	d.checkpoint.Finish("refunds events")
+	a.NoError(d.saveContext(context.Background()))
+
+	saved := savedContext{}
+	a.NoError(json.Unmarshal(client.context, &saved))
+	a.True(saved.PreviousRunTimestamp.Equal(previousRunTimestamp))
+	if a.NotNil(saved.Checkpoint) {
+		a.True(saved.Checkpoint.EventsSince.Equal(eventsSince))
+	}
+
+	// once the listings finish, events processed before the quota ran out are listed again
+	d = NewDispatcher(client)
+	d.SetAccount("acct_1", true)
+	d.SetQuota(api.NewQuota(100))
+	a.NoError(d.initContext(context.Background()))
+	a.True(d.resumed)
+	a.True(d.runContext.PreviousRunTimestamp.Equal(previousRunTimestamp))
+	progress, _ := d.checkpoint.Progress("refunds events")
+	a.True(progress.Done)
+	a.NoError(d.saveContext(context.Background()))
+
+	saved = savedContext{}
+	a.NoError(json.Unmarshal(client.context, &saved))
+	a.Nil(saved.Checkpoint)
+	a.True(saved.PreviousRunTimestamp.Equal(eventsSince))
+}
+
+func TestFirstRunWithReconciliation(t *testing.T) {
+	client := &mockSourceClient{}
+	d := NewDispatcher(client)
+	d.SetAccount("acct_1", true)
+	d.EnableReconciliation()
+	d.Register(newPagedResource(&pageQuota{limit: 10}, []string{"ch_2", "ch_1"}))
+
+	a := assert.New(t)
+	a.NoError(d.Run())
+	a.Len(client.sets, 2)
+
+	saved := savedContext{}
+	a.NoError(json.Unmarshal(client.context, &saved))
+	a.Equal([]string{"ch_1", "ch_2"}, saved.SyncedIds["charges"])
+}
@@ -0,0 +1,87 @@
+package integration
+
+import (
+	"github.com/apex/log"
+	"sort"
+	"sync"
+)
+
+// defaultSyncedIdsLimit is the number of ids remembered per collection, it's about 3 MB of a saved context
+const defaultSyncedIdsLimit = 100000
+
+// idSet is a set of object ids grouped by collection that's safe for concurrent use. If limit is positive,
+// new ids of a collection that has limit ids are ignored, so the set of such a collection is incomplete.
+type idSet struct {
+	mu    sync.Mutex
+	ids   map[string]map[string]struct{}
+	limit int
+	full  map[string]bool
+}
+
+func (s *idSet) Add(collection string, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ids[collection] == nil {
+		s.ids[collection] = map[string]struct{}{}
+	}
+	if _, ok := s.ids[collection][id]; ok {
+		return
+	}
+	if s.limit > 0 && len(s.ids[collection]) >= s.limit {
+		if !s.full[collection] {
+			s.full[collection] = true
+			log.WithFields(log.Fields{
+				"collection": collection,
+				"limit":      s.limit,
+			}).Warn("too many synced objects to remember, some of them can't be deleted")
+		}
+		return
+	}
+	s.ids[collection][id] = struct{}{}
+}
+
+func (s *idSet) Remove(collection string, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.ids[collection], id)
+}
+
+// Has returns true if the id was added to the collection
+func (s *idSet) Has(collection string, id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.ids[collection][id]
+	return ok
+}
+
+func (s *idSet) AddLists(lists map[string][]string) {
+	for collection, ids := range lists {
+		for _, id := range ids {
+			s.Add(collection, id)
+		}
+	}
+}
+
+// Lists returns sorted ids of every collection
+func (s *idSet) Lists() map[string][]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lists := map[string][]string{}
+	for collection, ids := range s.ids {
+		list := make([]string, 0, len(ids))
+		for id := range ids {
+			list = append(list, id)
+		}
+		sort.Strings(list)
+		lists[collection] = list
+	}
+	return lists
+}
+
+func newIdSet(limit int) *idSet {
+	return &idSet{
+		ids:   map[string]map[string]struct{}{},
+		limit: limit,
+		full:  map[string]bool{},
+	}
+}
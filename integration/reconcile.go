@@ -0,0 +1,86 @@
+package integration
+
+import (
+	"github.com/apex/log"
+	"sort"
+)
+
+// PartialListing is implemented by resources whose full sync doesn't download every object of their
+// collections, e.g. report runs covering an interval. Their objects aren't deleted by reconciliation.
+type PartialListing interface {
+	PartialListing() bool
+}
+
+// HasPartialCollections is implemented by resources that group other resources, e.g. bundles.
+// It returns the collections of members that implement PartialListing.
+type HasPartialCollections interface {
+	PartialCollections() []string
+}
+
+// reconciledCollections returns the collections of registered resources that list every object in a full sync
+func (d *Dispatcher) reconciledCollections() []string {
+	collectionSet := map[string]bool{}
+	partial := map[string]bool{}
+	for _, res := range d.resources {
+		if i, ok := res.(PartialListing); ok && i.PartialListing() {
+			continue
+		}
+		if i, ok := res.(HasPartialCollections); ok {
+			for _, collection := range i.PartialCollections() {
+				partial[collection] = true
+			}
+		}
+		for _, con := range res.Consumers() {
+			if !partial[con.Collection()] {
+				collectionSet[con.Collection()] = true
+			}
+		}
+	}
+
+	collections := []string{}
+	for collection := range collectionSet {
+		collections = append(collections, collection)
+	}
+	sort.Strings(collections)
+	return collections
+}
+
+// reconcileDeletions marks objects synced before a full sync that weren't synced again as deleted.
+// Collections that aren't synced anymore are left alone, and nothing is deleted unless the full sync
+// finished without errors, since objects may be missing only because they weren't downloaded.
+func (d *Dispatcher) reconcileDeletions() {
+	if d.seenIds == nil {
+		return
+	}
+
+	if d.producerFailures > 0 || d.collectionErrors > 0 || d.quotaExhausted() {
+		log.Warn("skipping reconciliation of deletions as the full sync didn't finish")
+		return
+	}
+	previousIds := d.previousIds.Lists()
+	if len(previousIds) < 1 {
+		log.Info("skipping reconciliation of deletions as previously synced objects are unknown")
+		return
+	}
+
+	for _, collection := range d.reconciledCollections() {
+		deleted := 0
+		for _, id := range previousIds[collection] {
+			if d.seenIds.Has(collection, id) {
+				continue
+			}
+			if err := d.sourceClient.Set(collection, id, map[string]interface{}{"is_deleted": true}); err != nil {
+				log.WithError(err).Fatal("Set call failed, aborting the sync")
+			}
+			d.syncedIds.Remove(collection, id)
+			deleted++
+		}
+
+		if deleted > 0 {
+			log.WithFields(log.Fields{
+				"collection": collection,
+				"count":      deleted,
+			}).Info("deleted objects that weren't found in the full sync")
+		}
+	}
+}
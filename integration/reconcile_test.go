@@ -0,0 +1,106 @@
+package integration
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type partialResource struct {
+	scheduledResource
+}
+
+func (r *partialResource) PartialListing() bool {
+	return true
+}
+
+func newReconcilingDispatcher(t *testing.T, client *mockSourceClient) *Dispatcher {
+	d := NewDispatcher(client)
+	d.SetAccount("acct_1", true)
+	d.EnableReconciliation()
+	d.resources = []Resource{
+		&scheduledResource{collection: "charges"},
+		&partialResource{scheduledResource{collection: "report_rows"}},
+	}
+	if err := d.initContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// a full sync that downloaded one of the previously synced charges
+	d.seenIds = newIdSet(0)
+	for _, ids := range []*idSet{d.seenIds, d.syncedIds} {
+		ids.Add("charges", "ch_2")
+		ids.Add("charges", "ch_3")
+	}
+	return d
+}
+
+func TestReconcileDeletions(t *testing.T) {
+	client := &mockSourceClient{context: makeContext(t, "acct_1", true)}
+	d := newReconcilingDispatcher(t, client)
+	d.previousIds.Add("report_rows", "row_1")
+
+	d.reconcileDeletions()
+
+	a := assert.New(t)
+	a.Equal([]setCall{
+		{"charges", "ch_1", map[string]interface{}{"is_deleted": true}},
+	}, client.sets)
+	a.Equal([]string{"ch_2", "ch_3"}, d.syncedIds.Lists()["charges"])
+}
+
+func TestReconcileDeletionsAfterFailure(t *testing.T) {
+	client := &mockSourceClient{context: makeContext(t, "acct_1", true)}
+	d := newReconcilingDispatcher(t, client)
+	d.collectionErrors = 1
+
+	d.reconcileDeletions()
+
+	a := assert.New(t)
+	a.Empty(client.sets)
+	a.Equal([]string{"ch_1", "ch_2", "ch_3"}, d.syncedIds.Lists()["charges"])
+}
+
+func TestReconciliationWithoutTombstones(t *testing.T) {
+	client := &mockSourceClient{context: makeContext(t, "acct_1", true)}
+	d := NewDispatcher(client)
+	d.SetAccount("acct_2", true)
+	d.EnableReconciliation()
+
+	// switching accounts doesn't delete objects unless tombstones are enabled
+	assert.NoError(t, d.initContext(context.Background()))
+	d.sendTombstones()
+	assert.Empty(t, client.sets)
+}
+
+// groupResource groups resources like a bundle does
+type groupResource struct {
+	Resource
+	members []*scheduledResource
+	partial []string
+}
+
+func (r *groupResource) Consumers() []Consumer {
+	consumers := []Consumer{}
+	for _, member := range r.members {
+		consumers = append(consumers, member)
+	}
+	return consumers
+}
+
+func (r *groupResource) PartialCollections() []string {
+	return r.partial
+}
+
+func TestReconciledCollectionsOfGroups(t *testing.T) {
+	d := NewDispatcher(&mockSourceClient{})
+	d.resources = []Resource{
+		&groupResource{
+			members: []*scheduledResource{{collection: "reviews"}, {collection: "early_fraud_warnings"}},
+			partial: []string{"reviews"},
+		},
+		&scheduledResource{collection: "charges"},
+	}
+
+	assert.Equal(t, []string{"charges", "early_fraud_warnings"}, d.reconciledCollections())
+}
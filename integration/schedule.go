@@ -0,0 +1,164 @@
+package integration
+
+import (
+	"context"
+	"github.com/apex/log"
+	"github.com/segment-sources/stripe/api"
+	"strings"
+	"sync"
+)
+
+const defaultPriority = 1
+
+// Schedule describes when a resource's producer runs relative to other producers
+type Schedule struct {
+	// Priority is the weight of the producer's share of the request rate, small reference collections
+	// should have a higher priority so that they're synced early. Producers without a schedule have priority 1.
+	Priority int
+	// After lists collections whose producers must finish before this producer starts,
+	// collections that aren't synced are ignored
+	After []string
+}
+
+// HasSchedule is implemented by resources that declare their priority or dependencies
+type HasSchedule interface {
+	Schedule() Schedule
+}
+
+// Scheduler delays producers until their dependencies are finished and throttles their requests
+// according to their priority. Priorities of collections can be overridden by operators, who can also
+// give collections a budget of requests per second carved from the client's rate.
+type Scheduler struct {
+	mu         sync.Mutex
+	finished   map[string]chan struct{}
+	priorities map[string]int
+	budgets    map[string]int
+}
+
+type schedulerKey struct{}
+
+// register creates a dependency for every collection of a resource, so that it's known
+// which collections are synced before any producer starts
+func (s *Scheduler) register(res Resource) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, con := range res.Consumers() {
+		if _, ok := s.finished[con.Collection()]; !ok {
+			s.finished[con.Collection()] = make(chan struct{})
+		}
+	}
+}
+
+func (s *Scheduler) schedule(res Resource) Schedule {
+	schedule := Schedule{Priority: defaultPriority}
+	if i, ok := res.(HasSchedule); ok {
+		schedule = i.Schedule()
+	}
+
+	if priority, ok := s.priorities[collectionName(res)]; ok {
+		schedule.Priority = priority
+	}
+	if schedule.Priority < 1 {
+		schedule.Priority = defaultPriority
+	}
+
+	return schedule
+}
+
+func (s *Scheduler) wait(ctx context.Context, collections []string) {
+	for _, collection := range collections {
+		s.mu.Lock()
+		finished, ok := s.finished[collection]
+		s.mu.Unlock()
+		if !ok {
+			continue
+		}
+
+		select {
+		case <-finished:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *Scheduler) finish(res Resource) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, con := range res.Consumers() {
+		if finished, ok := s.finished[con.Collection()]; ok {
+			select {
+			case <-finished:
+			default:
+				close(finished)
+			}
+		}
+	}
+}
+
+// StartScheduled waits until the producers that res depends on are finished and returns a context whose
+// requests use res's share of the request rate. The returned function must be called once res's producer
+// is done. If the context doesn't have a scheduler, res is started immediately.
+func StartScheduled(ctx context.Context, res Resource) (context.Context, func()) {
+	s, ok := ctx.Value(schedulerKey{}).(*Scheduler)
+	if !ok {
+		return ctx, func() {}
+	}
+
+	schedule := s.schedule(res)
+	if len(schedule.After) > 0 {
+		logger := log.WithFields(log.Fields{
+			"collection": collectionName(res),
+			"after":      schedule.After,
+		})
+		logger.Info("waiting for dependencies of producer")
+		s.wait(ctx, schedule.After)
+		logger.Info("dependencies of producer finished")
+	}
+
+	// a bundle's stream of events uses the budget of its first collection
+	ctx = api.WithShare(ctx, shareName(res), schedule.Priority, s.budgets[collectionName(res)])
+	return ctx, func() { s.finish(res) }
+}
+
+// collectionName is the name of a resource's first consumer, it's used to override the resource's priority
+func collectionName(res Resource) string {
+	for _, con := range res.Consumers() {
+		return con.Collection()
+	}
+
+	return ""
+}
+
+// shareName identifies a resource's share of the request rate, a bundle's share is separate
+// from the shares of its members
+func shareName(res Resource) string {
+	names := []string{}
+	for _, con := range res.Consumers() {
+		names = append(names, con.Collection())
+	}
+
+	return strings.Join(names, "+")
+}
+
+// WithScheduler returns a context in which StartScheduled uses the scheduler
+func WithScheduler(ctx context.Context, s *Scheduler) context.Context {
+	return context.WithValue(ctx, schedulerKey{}, s)
+}
+
+func NewScheduler(priorities map[string]int, budgets map[string]int) *Scheduler {
+	if priorities == nil {
+		priorities = map[string]int{}
+	}
+	if budgets == nil {
+		budgets = map[string]int{}
+	}
+
+	return &Scheduler{
+		finished:   map[string]chan struct{}{},
+		priorities: priorities,
+		budgets:    budgets,
+	}
+}
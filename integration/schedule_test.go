@@ -0,0 +1,75 @@
+package integration
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// scheduledResource has a single consumer and doesn't produce anything
+type scheduledResource struct {
+	Resource
+	Consumer
+	collection string
+	schedule   Schedule
+}
+
+func (r *scheduledResource) Collection() string {
+	return r.collection
+}
+
+func (r *scheduledResource) Consumers() []Consumer {
+	return []Consumer{r}
+}
+
+func (r *scheduledResource) Schedule() Schedule {
+	return r.schedule
+}
+
+func TestSchedulerDependencies(t *testing.T) {
+	customers := &scheduledResource{collection: "customers", schedule: Schedule{Priority: 2}}
+	subscriptions := &scheduledResource{collection: "subscriptions", schedule: Schedule{After: []string{"customers", "plans"}}}
+
+	s := NewScheduler(nil, nil)
+	s.register(customers)
+	s.register(subscriptions)
+	ctx := WithScheduler(context.Background(), s)
+
+	started := make(chan struct{})
+	go func() {
+		_, finish := StartScheduled(ctx, subscriptions)
+		defer finish()
+		close(started)
+	}()
+
+	_, finish := StartScheduled(ctx, customers)
+	select {
+	case <-started:
+		t.Fatal("subscriptions started before customers finished")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	finish()
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("subscriptions didn't start after customers finished")
+	}
+}
+
+func TestSchedulerPriorities(t *testing.T) {
+	s := NewScheduler(map[string]int{"charges": 3}, nil)
+
+	a := assert.New(t)
+	a.Equal(2, s.schedule(&scheduledResource{collection: "customers", schedule: Schedule{Priority: 2}}).Priority)
+	a.Equal(3, s.schedule(&scheduledResource{collection: "charges"}).Priority)
+	a.Equal(1, s.schedule(&scheduledResource{collection: "refunds"}).Priority)
+}
+
+func TestStartScheduledWithoutScheduler(t *testing.T) {
+	res := &scheduledResource{collection: "subscriptions", schedule: Schedule{After: []string{"customers"}}}
+	ctx, finish := StartScheduled(context.Background(), res)
+	finish()
+	assert.NotNil(t, ctx)
+}
@@ -31,6 +31,30 @@ type Resource interface {
 type RunContext struct {
 	PreviousRunTimestamp time.Time `json:"previous_run_timestamp"`
 	Version              int       `json:"version"`
+	AccountId            string    `json:"account_id,omitempty"`
+	Livemode             bool      `json:"livemode"`
+	// StaleRunTimestamp is the timestamp of a previous run that's too old for an incremental sync
+	// based on events, resources that can list or search for objects created since then use it
+	StaleRunTimestamp time.Time `json:"-"`
+	// Backfill selects collections that should download objects created in a time range in incremental mode
+	Backfill *Backfill `json:"-"`
+}
+
+// savedContext is the document stored between runs. Ids of synced objects are only stored
+// if tombstones are enabled since they're needed to delete objects of a previous account.
+// Backfills are a record of the most recent backfills. Checkpoint is only stored if the request quota
+// was exhausted, the next run continues the sync from it.
+type savedContext struct {
+	RunContext
+	SyncedIds  map[string][]string `json:"synced_ids,omitempty"`
+	Backfills  []BackfillReport    `json:"backfills,omitempty"`
+	Checkpoint *Checkpoint         `json:"checkpoint,omitempty"`
+}
+
+// Quota is the quota of requests of a run, e.g. *api.Quota
+type Quota interface {
+	Exhausted() bool
+	Used() int64
 }
 
 type subscription struct {
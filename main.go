@@ -2,23 +2,31 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"github.com/apex/log"
 	"github.com/apex/log/handlers/json"
 	"github.com/pkg/errors"
-	"github.com/segmentio/stats/datadog"
+	"github.com/segment-sources/stripe/api"
+	"github.com/segment-sources/stripe/integration"
+	"github.com/segment-sources/stripe/resource"
+	"github.com/segment-sources/stripe/resource/bundle"
+	"github.com/segment-sources/stripe/resource/downloader"
+	"github.com/segment-sources/stripe/resource/probe"
+	"github.com/segment-sources/stripe/resource/tr"
 	"github.com/segmentio/conf"
+	"github.com/segmentio/ecs-logs-go/apex"
+	"github.com/segmentio/ecs-logs-go/log"
 	"github.com/segmentio/go-source"
+	"github.com/segmentio/stats"
+	"github.com/segmentio/stats/datadog"
+	stdlog "log"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"time"
-	"github.com/segmentio/ecs-logs-go/log"
-	"github.com/segmentio/ecs-logs-go/apex"
-	"github.com/segmentio/stats"
-	stdlog "log"
 )
 
-
 const (
 	Program = "hello-world-source"
 	Version = "0.0.1"
@@ -35,7 +43,6 @@ func initLogger() {
 	})
 }
 
-
 func setupLogging(cfg *config) {
 	handler := log_ecslogs.NewHandler(os.Stdout)
 	writer := log_ecslogs.NewWriter("", stdlog.Flags(), handler)
@@ -49,30 +56,68 @@ func setupLogging(cfg *config) {
 	})
 }
 
-func setupStats(cfg *config) {Program, stats.Discard, []stats.Tag{
-	{Name: "program", Value: Program},
-	stats.DefaultEngine = stats.NewEngine(
+func setupStats(cfg *config) {
+	stats.DefaultEngine = stats.NewEngine(Program, stats.Discard, []stats.Tag{
+		{Name: "program", Value: Program},
 		{Name: "version", Value: Version},
 	}...)
 	stats.Register(datadog.NewClient(cfg.DatadogAddr))
 }
 
 type config struct {
-	Secret          string
-	SetTransferId   bool
-	DisableAccounts bool
-	Rps             int
-	DatadogAddr     string
-	LogLevel        string
+	Secret            string
+	ApiVersion        string
+	SetTransferId     bool
+	DisableAccounts   bool
+	EnableIssuing     bool
+	EnableCashBalance bool
+	ForbidTestKeys    bool
+	Tombstones        bool
+	Reconcile         bool
+	SyncedIdsLimit    int
+	ReportTypes       []string
+	ReportInterval    time.Duration
+	Rps               int
+	FullSyncWorkers   int
+	ProcessorWorkers  int
+	SearchCollections map[string]bool
+	Priorities        map[string]int
+	Budgets           map[string]int
+	RequestQuota      int
+	Backfill          *integration.Backfill
+	StaleContextAge   time.Duration
+	LogListPayloads   bool
+	DatadogAddr       string
+	LogLevel          string
 }
 
 func parseConfig() *config {
 	rawCfg := struct {
-		Secret          string `conf:"secret"`
-		SetTransferId   string `conf:"set-transfer-id"`
-		DisableAccounts string `conf:"disable-accounts"`
-		Rps             int    `conf:"rps"`
-	}{Rps: 80}
+		Secret              string `conf:"secret"`
+		ApiVersion          string `conf:"api-version"`
+		SetTransferId       string `conf:"set-transfer-id"`
+		DisableAccounts     string `conf:"disable-accounts"`
+		EnableIssuing       string `conf:"enable-issuing"`
+		EnableCashBalance   string `conf:"enable-cash-balance"`
+		ForbidTestKeys      string `conf:"forbid-test-keys"`
+		Tombstones          string `conf:"tombstone-on-account-switch"`
+		Reconcile           string `conf:"reconcile-deletions"`
+		SyncedIdsLimit      int    `conf:"synced-ids-limit"`
+		ReportTypes         string `conf:"report-types"`
+		ReportIntervalDays  int    `conf:"report-interval-days"`
+		Rps                 int    `conf:"rps"`
+		FullSyncWorkers     int    `conf:"full-sync-workers"`
+		ProcessorWorkers    int    `conf:"processor-workers"`
+		SearchCollections   string `conf:"search-collections"`
+		Priorities          string `conf:"collection-priorities"`
+		Budgets             string `conf:"collection-rps"`
+		RequestQuota        int    `conf:"request-quota"`
+		BackfillCollections string `conf:"backfill-collections"`
+		BackfillFrom        string `conf:"backfill-from"`
+		BackfillTo          string `conf:"backfill-to"`
+		StaleContextDays    int    `conf:"stale-context-days"`
+		LogListPayloads     string `conf:"log-list-payloads"`
+	}{Rps: 80, FullSyncWorkers: 1, ProcessorWorkers: 1, ReportIntervalDays: 30, StaleContextDays: 30, SyncedIdsLimit: 100000, ApiVersion: api.DefaultApiVersion}
 
 	conf.LoadWith(&rawCfg, conf.Loader{
 		Name:    Program,
@@ -82,17 +127,99 @@ func parseConfig() *config {
 
 	setTransferId := strings.ToLower(rawCfg.SetTransferId)
 	disableAccounts := strings.ToLower(rawCfg.DisableAccounts)
+	enableIssuing := strings.ToLower(rawCfg.EnableIssuing)
+	enableCashBalance := strings.ToLower(rawCfg.EnableCashBalance)
+	forbidTestKeys := strings.ToLower(rawCfg.ForbidTestKeys)
+	tombstones := strings.ToLower(rawCfg.Tombstones)
+	reconcile := strings.ToLower(rawCfg.Reconcile)
+	logListPayloads := strings.ToLower(rawCfg.LogListPayloads)
+	var reportTypes []string
+	for _, reportType := range strings.Split(rawCfg.ReportTypes, ",") {
+		if reportType = strings.TrimSpace(reportType); reportType != "" {
+			reportTypes = append(reportTypes, reportType)
+		}
+	}
+	searchCollections := map[string]bool{}
+	for _, collection := range strings.Split(rawCfg.SearchCollections, ",") {
+		if collection = strings.TrimSpace(collection); collection != "" {
+			searchCollections[collection] = true
+		}
+	}
 	return &config{
-		Secret:          rawCfg.Secret,
-		Rps:             rawCfg.Rps,
-		SetTransferId:   setTransferId == "1" || setTransferId == "yes" || setTransferId == "true",
-		DisableAccounts: disableAccounts == "1" || disableAccounts == "yes" || disableAccounts == "true",
-		DatadogAddr: "127.0.0.1:8125",
-		LogLevel: "INFO",
+		Secret:            rawCfg.Secret,
+		ApiVersion:        rawCfg.ApiVersion,
+		Rps:               rawCfg.Rps,
+		FullSyncWorkers:   rawCfg.FullSyncWorkers,
+		ProcessorWorkers:  rawCfg.ProcessorWorkers,
+		SearchCollections: searchCollections,
+		Priorities:        parseCollectionValues(rawCfg.Priorities, "collection-priorities"),
+		Budgets:           parseCollectionValues(rawCfg.Budgets, "collection-rps"),
+		RequestQuota:      rawCfg.RequestQuota,
+		Backfill:          parseBackfill(rawCfg.BackfillCollections, rawCfg.BackfillFrom, rawCfg.BackfillTo),
+		SetTransferId:     setTransferId == "1" || setTransferId == "yes" || setTransferId == "true",
+		DisableAccounts:   disableAccounts == "1" || disableAccounts == "yes" || disableAccounts == "true",
+		EnableIssuing:     enableIssuing == "1" || enableIssuing == "yes" || enableIssuing == "true",
+		EnableCashBalance: enableCashBalance == "1" || enableCashBalance == "yes" || enableCashBalance == "true",
+		ForbidTestKeys:    forbidTestKeys == "1" || forbidTestKeys == "yes" || forbidTestKeys == "true",
+		Tombstones:        tombstones == "1" || tombstones == "yes" || tombstones == "true",
+		Reconcile:         reconcile == "1" || reconcile == "yes" || reconcile == "true",
+		SyncedIdsLimit:    rawCfg.SyncedIdsLimit,
+		LogListPayloads:   logListPayloads == "1" || logListPayloads == "yes" || logListPayloads == "true",
+		ReportTypes:       reportTypes,
+		ReportInterval:    time.Duration(rawCfg.ReportIntervalDays) * time.Hour * 24,
+		StaleContextAge:   time.Duration(rawCfg.StaleContextDays) * time.Hour * 24,
+		DatadogAddr:       "127.0.0.1:8125",
+		LogLevel:          "INFO",
+	}
+}
+
+// parseCollectionValues parses a comma-separated list of collection=value with positive values
+func parseCollectionValues(list string, option string) map[string]int {
+	values := map[string]int{}
+	for _, entry := range strings.Split(list, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		value, err := strconv.Atoi(strings.TrimSpace(parts[len(parts)-1]))
+		if len(parts) != 2 || err != nil || value < 1 {
+			log.WithFields(log.Fields{"option": option, "entry": entry}).Fatal("invalid entry, expected collection=value")
+		}
+		values[strings.TrimSpace(parts[0])] = value
 	}
+	return values
 }
 
+// parseBackfill returns nil if no collections were selected, the range is given as dates or RFC 3339 timestamps
+func parseBackfill(collections string, from string, to string) *integration.Backfill {
+	backfill := &integration.Backfill{Collections: map[string]bool{}}
+	for _, collection := range strings.Split(collections, ",") {
+		if collection = strings.TrimSpace(collection); collection != "" {
+			backfill.Collections[collection] = true
+		}
+	}
+	if len(backfill.Collections) < 1 {
+		return nil
+	}
 
+	parse := func(option string, value string) time.Time {
+		for _, layout := range []string{"2006-01-02", time.RFC3339} {
+			if t, err := time.Parse(layout, value); err == nil {
+				return t
+			}
+		}
+		log.WithFields(log.Fields{"option": option, "value": value}).Fatal("invalid backfill time, expected a date or an RFC 3339 timestamp")
+		return time.Time{}
+	}
+	if from == "" {
+		log.Fatal("backfill-from is required to backfill collections")
+	}
+	backfill.From = parse("backfill-from", from)
+	if to != "" {
+		backfill.To = parse("backfill-to", to)
+	}
+	return backfill
+}
 
 func main() {
 	// Basic Setup
@@ -112,27 +239,54 @@ func main() {
 		log.WithError(err).Fatal("keepalive call failed")
 	}
 
+	// budgets are carved from the client's rate, they can't add up to more than that
+	budgetsTotal := 0
+	for _, rps := range cfg.Budgets {
+		budgetsTotal += rps
+	}
+	if budgetsTotal > cfg.Rps {
+		log.WithFields(log.Fields{"budgets": budgetsTotal, "rps": cfg.Rps}).Warn("collection budgets exceed the request rate")
+	}
+
+	// events older than 30 days aren't retained, changes made before then would be missed
+	if cfg.StaleContextAge > time.Hour*24*30 {
+		log.WithField("stale_context_days", cfg.StaleContextAge.Hours()/24).Warn("stale context threshold exceeds the events retention")
+	}
+
 	// initialize api client
+	quota := api.NewQuota(cfg.RequestQuota)
 	apiClient := api.NewClient(&api.ClientOptions{
-		Secret:       cfg.Secret,
-		BaseUrl:      "helloworld", //TODO: We don't need an API client yet, but we will
-		HttpClient:   &http.Client{Timeout: time.Minute * 5},
-		MaxRps:       cfg.Rps,
-		SourceClient: sourceClient,
+		Secret:          cfg.Secret,
+		HttpClient:      &http.Client{Timeout: time.Minute * 5},
+		MaxRps:          cfg.Rps,
+		SourceClient:    sourceClient,
+		ApiVersion:      cfg.ApiVersion,
+		LogListPayloads: cfg.LogListPayloads,
+		DebugLogging:    log.MustParseLevel(cfg.LogLevel) <= log.DebugLevel,
+		Quota:           quota,
 	})
 
-	if cfg.Secret == "" {
-		errorMsg := "Invalid credentials (no credentials found)"
+	accountId, errorMsg := verifyCredentials(context.Background(), apiClient, cfg)
+	if errorMsg != "" {
 		log.Error(errorMsg)
 		sourceClient.Log().Error("", "authentication", errors.New(errorMsg))
 		sourceClient.ReportError(errorMsg, "")
 		return
 	}
 
-	// TODO: API test
-
 	// run dispatcher
 	d := initDispatcher(apiClient, sourceClient, cfg)
+	d.SetAccount(accountId, !isTestKey(cfg.Secret))
+	d.SetQuota(quota)
+	d.SetBackfill(cfg.Backfill)
+	d.SetStaleContextThreshold(cfg.StaleContextAge)
+	d.SetSyncedIdsLimit(cfg.SyncedIdsLimit)
+	if cfg.Tombstones {
+		d.EnableTombstones()
+	}
+	if cfg.Reconcile {
+		d.EnableReconciliation()
+	}
 	if err := d.Run(); err != nil {
 		log.WithError(err).Fatal("Run failed")
 	}
@@ -141,53 +295,183 @@ func main() {
 
 }
 
+func isTestKey(secret string) bool {
+	return strings.HasPrefix(secret, "sk_test_") || strings.HasPrefix(secret, "rk_test_")
+}
+
+// verifyCredentials requests the Stripe account that the API key belongs to and returns its id.
+// If the key can't be used for syncing, a message describing how to fix it is returned instead.
+func verifyCredentials(ctx context.Context, apiClient api.Client, cfg *config) (string, string) {
+	if cfg.Secret == "" {
+		return "", "Invalid credentials (no credentials found)"
+	}
+
+	if cfg.ForbidTestKeys && isTestKey(cfg.Secret) {
+		return "", "Invalid credentials (test mode API key provided), please use a live mode secret or restricted key"
+	}
+
+	account, err := downloader.RetryGetObject(ctx, apiClient, &api.Request{
+		Url: "/v1/account",
+	})
+	switch {
+	case err == nil:
+	case api.IsErrorPermissionRelated(err):
+		// restricted keys may not be allowed to read the account, the sync can still proceed
+		log.WithError(err).Warn("API key isn't allowed to read the account")
+		return "", ""
+	case api.IsErrorAuthRelated(err):
+		if stripeErr := api.GetStripeError(err); stripeErr != nil && stripeErr.Code == "api_key_expired" {
+			return "", "Invalid credentials (the API key was revoked or has expired), please create a new key in the Stripe Dashboard"
+		}
+		return "", "Invalid credentials (the API key wasn't accepted by Stripe), please check that the whole key was copied"
+	default:
+		log.WithError(err).Error("API test failed")
+		return "", fmt.Sprintf("Stripe API test failed: %s", downloader.ErrorMessage(err))
+	}
+
+	// accounts rejected by Stripe (e.g. for fraud or terms of service violations) are disabled,
+	// versions before 2019-02-19 report the reason in verification instead of requirements
+	reason := tr.GetString(tr.GetMap(account, "requirements"), "disabled_reason")
+	if reason == "" {
+		reason = tr.GetString(tr.GetMap(account, "verification"), "disabled_reason")
+	}
+	if strings.HasPrefix(reason, "rejected.") {
+		return "", fmt.Sprintf("The Stripe account has been disabled (%s), please contact Stripe support", reason)
+	}
+
+	return tr.GetString(account, "id"), ""
+}
+
 func initDispatcher(apiClient api.Client, sourceClient source.Client, cfg *config) *integration.Dispatcher {
 	d := integration.NewDispatcher(sourceClient)
+	d.SetSchedule(cfg.Priorities, cfg.Budgets)
+
+	// restricted API keys may not be allowed to read every collection, only the permitted resources are registered
+	ctx := context.Background()
+	p := probe.New(apiClient, sourceClient)
+	register := func(res integration.Resource) {
+		for _, permitted := range p.Permitted(ctx, res) {
+			d.Register(permitted)
+		}
+	}
+	registerBundle := func(resources ...integration.Resource) []integration.Resource {
+		permitted := p.Permitted(ctx, resources...)
+		if len(permitted) > 0 {
+			d.Register(bundle.New(apiClient, cfg.ProcessorWorkers, permitted...))
+		}
+		return permitted
+	}
 
 	if !cfg.DisableAccounts {
-		d.Register(resource.NewAccount(apiClient))
+		registerBundle(
+			resource.NewAccount(apiClient),
+			resource.NewAccountPerson(apiClient),
+			resource.NewAccountCapability(apiClient),
+			resource.NewAccountExternalAccount(apiClient),
+		)
 	}
 
-	d.Register(bundle.New(apiClient,
-		resource.NewTransfer(apiClient, cfg.SetTransferId),
+	registerBundle(
+		resource.NewTransfer(apiClient, cfg.SetTransferId, cfg.ProcessorWorkers),
 		resource.NewTransferReversal(apiClient),
-	))
+	)
 
-	d.Register(bundle.New(apiClient,
-		resource.NewCharge(apiClient),
+	// charges are listed together with their balance transactions if the key may read them,
+	// the balance history listing of a full sync then leaves the transactions of charges out
+	balanceTransaction := resource.NewBalanceTransaction(apiClient, cfg.SetTransferId)
+	balanceTransactionsPermitted := len(p.Permitted(ctx, balanceTransaction)) > 0
+	charge := resource.NewCharge(apiClient, cfg.FullSyncWorkers, cfg.SearchCollections["charges"], balanceTransactionsPermitted)
+	chargeBundle := registerBundle(
+		charge,
 		resource.NewRefund(apiClient),
 		resource.NewCard(apiClient),
 		resource.NewBankAccount(apiClient),
-	))
+	)
+	if balanceTransactionsPermitted && len(chargeBundle) > 0 && chargeBundle[0] == charge {
+		balanceTransaction.SkipChargeTransactions()
+	}
 
-	d.Register(bundle.New(apiClient,
-		resource.NewSubscription(apiClient),
+	registerBundle(
+		resource.NewSubscription(apiClient, cfg.SearchCollections["subscriptions"]),
 		resource.NewSubscriptionItem(apiClient),
 		resource.NewPlan(apiClient),
-		resource.NewInvoice(apiClient),
+		resource.NewInvoice(apiClient, cfg.FullSyncWorkers, cfg.SearchCollections["invoices"]),
 		resource.NewInvoiceLine(apiClient),
 		resource.NewDiscount(apiClient),
 		resource.NewCoupon(apiClient),
-	))
+	)
 
-	d.Register(bundle.New(apiClient,
+	registerBundle(
 		resource.NewOrder(apiClient),
 		resource.NewOrderShippingMethod(apiClient),
-	))
+	)
 
-	d.Register(bundle.New(apiClient,
+	registerBundle(
 		resource.NewApplicationFee(apiClient),
 		resource.NewApplicationFeeRefund(apiClient),
-	))
-
-	d.Register(resource.NewBalanceTransaction(apiClient, cfg.SetTransferId))
-	d.Register(resource.NewBalanceTransactionFeeDetail(apiClient))
-	d.Register(resource.NewCustomer(apiClient))
-	d.Register(resource.NewInvoiceItem(apiClient))
-	d.Register(resource.NewDispute(apiClient))
-	d.Register(resource.NewProduct(apiClient))
-	d.Register(resource.NewSku(apiClient))
-	d.Register(resource.NewOrderReturn(apiClient))
+	)
+
+	registerBundle(
+		resource.NewCheckoutSession(apiClient),
+		resource.NewCheckoutSessionLineItem(apiClient),
+	)
+
+	registerBundle(
+		resource.NewQuote(apiClient),
+		resource.NewQuoteLineItem(apiClient),
+	)
+
+	registerBundle(
+		resource.NewReview(apiClient),
+		resource.NewEarlyFraudWarning(apiClient),
+		resource.NewRadarValueList(apiClient),
+		resource.NewRadarValueListItem(apiClient),
+	)
+
+	registerBundle(
+		resource.NewTerminalLocation(apiClient),
+		resource.NewTerminalReader(apiClient),
+		resource.NewTerminalConfiguration(apiClient),
+	)
+
+	// most accounts don't have Issuing enabled and would get permission errors for these endpoints
+	if cfg.EnableIssuing {
+		registerBundle(
+			resource.NewIssuingCardholder(apiClient),
+			resource.NewIssuingCard(apiClient),
+			resource.NewIssuingAuthorization(apiClient),
+			resource.NewIssuingAuthorizationRequest(apiClient),
+			resource.NewIssuingTransaction(apiClient),
+			resource.NewIssuingDispute(apiClient),
+		)
+	}
+
+	for _, reportType := range cfg.ReportTypes {
+		register(resource.NewReportRun(apiClient, reportType, cfg.ReportInterval))
+	}
+
+	if balanceTransactionsPermitted {
+		d.Register(balanceTransaction)
+	}
+	register(resource.NewBalanceTransactionFeeDetail(apiClient))
+	customerResources := []integration.Resource{
+		resource.NewCustomer(apiClient, cfg.FullSyncWorkers, cfg.SearchCollections["customers"], cfg.EnableCashBalance),
+		resource.NewCustomerBalanceTransaction(apiClient),
+		resource.NewCustomerTaxId(apiClient),
+	}
+	// cash balances are only used for bank transfer payments, listing them would cost a request per customer otherwise
+	if cfg.EnableCashBalance {
+		customerResources = append(customerResources, resource.NewCustomerCashBalanceTransaction(apiClient))
+	}
+	registerBundle(customerResources...)
+	register(resource.NewInvoiceItem(apiClient))
+	register(resource.NewDispute(apiClient))
+	register(resource.NewProduct(apiClient, cfg.SearchCollections["products"]))
+	register(resource.NewSku(apiClient))
+	register(resource.NewOrderReturn(apiClient))
+	register(resource.NewPaymentLink(apiClient))
+
+	p.LogSummary()
 
 	return d
 }
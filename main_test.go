@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"github.com/segment-sources/stripe/api"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// accountClient responds to account requests with a fixed account or error
+type accountClient struct {
+	api.Client
+	account api.Object
+	err     error
+}
+
+func (c *accountClient) GetObject(ctx context.Context, req *api.Request) (api.Object, error) {
+	return c.account, c.err
+}
+
+func TestVerifyCredentials(t *testing.T) {
+	cfg := &config{Secret: "sk_live_123"}
+
+	tests := []struct {
+		name      string
+		client    *accountClient
+		accountId string
+		errorMsg  string
+	}{
+		{
+			name:      "ok",
+			client:    &accountClient{account: api.Object{"id": "acct_1", "verification": map[string]interface{}{"disabled_reason": nil}}},
+			accountId: "acct_1",
+		},
+		{
+			name:     "expired key",
+			client:   &accountClient{err: &api.StripeError{StatusCode: 401, Type: "invalid_request_error", Code: "api_key_expired"}},
+			errorMsg: "Invalid credentials (the API key was revoked or has expired), please create a new key in the Stripe Dashboard",
+		},
+		{
+			name:     "invalid key",
+			client:   &accountClient{err: &api.StripeError{StatusCode: 401, Type: "invalid_request_error"}},
+			errorMsg: "Invalid credentials (the API key wasn't accepted by Stripe), please check that the whole key was copied",
+		},
+		{
+			name:     "disabled account",
+			client:   &accountClient{account: api.Object{"id": "acct_1", "verification": map[string]interface{}{"disabled_reason": "rejected.fraud"}}},
+			errorMsg: "The Stripe account has been disabled (rejected.fraud), please contact Stripe support",
+		},
+		{
+			name:     "disabled account with requirements",
+			client:   &accountClient{account: api.Object{"id": "acct_1", "requirements": map[string]interface{}{"disabled_reason": "rejected.terms_of_service"}}},
+			errorMsg: "The Stripe account has been disabled (rejected.terms_of_service), please contact Stripe support",
+		},
+		{
+			name:      "account with pending requirements",
+			client:    &accountClient{account: api.Object{"id": "acct_1", "requirements": map[string]interface{}{"disabled_reason": "requirements.past_due"}}},
+			accountId: "acct_1",
+		},
+	}
+
+	for _, test := range tests {
+		accountId, errorMsg := verifyCredentials(context.Background(), test.client, cfg)
+		assert.Equal(t, test.accountId, accountId, test.name)
+		assert.Equal(t, test.errorMsg, errorMsg, test.name)
+	}
+}
@@ -2,15 +2,22 @@ package resource
 
 import (
 	"context"
+	"github.com/apex/log"
 	"github.com/segment-sources/stripe/api"
 	"github.com/segment-sources/stripe/integration"
 	"github.com/segment-sources/stripe/resource/dedupe"
 	"github.com/segment-sources/stripe/resource/downloader"
+	"github.com/segment-sources/stripe/resource/processors"
 	"github.com/segment-sources/stripe/resource/tr"
 	"github.com/segmentio/go-source"
 	"github.com/segmentio/ur-log"
+	"strings"
 )
 
+var accountEvents = []string{
+	"account.updated",
+}
+
 type Account struct {
 	name      string
 	apiClient api.Client
@@ -25,10 +32,15 @@ func (r *Account) DesiredObjects() []string {
 }
 
 func (r *Account) DesiredEvents() []string {
-	return nil
+	return accountEvents
+}
+
+func (r *Account) ListEndpoint() string {
+	return "/v1/accounts"
 }
 
-// StartProducer for Account always performs a full sync
+// StartProducer for Account always pulls the primary account and every connected account together with
+// their persons, capabilities and external accounts, updates are also received from the bundle's events
 func (r *Account) StartProducer(ctx context.Context, runContext integration.RunContext) error {
 	defer close(r.objs)
 	defer close(r.errs)
@@ -41,13 +53,13 @@ func (r *Account) StartProducer(ctx context.Context, runContext integration.RunC
 	if err != nil {
 		r.errs <- integration.CollectionError{
 			Collection: r.name,
-			Message:    "HTTP request failed",
+			Message:    downloader.ErrorMessage(err),
 		}
 		return urlog.WrapError(ctx, err, "failed to fetch primary account")
 	}
 	r.objs <- primaryAcct
 
-	// pull all the Stripe Connect accounts
+	// pull all the Stripe Connect accounts, there are no events for accounts that were connected since the previous run
 	d := downloader.New(r.apiClient)
 	req := &api.Request{
 		Url:           "/v1/accounts?limit=100",
@@ -58,6 +70,11 @@ func (r *Account) StartProducer(ctx context.Context, runContext integration.RunC
 		Request:    req,
 		Output:     r.objs,
 		Errors:     r.errs,
+		PostProcessors: []downloader.PostProcessor{
+			skipAccountErrors(processors.NewListExpander("external_accounts", r.apiClient)),
+			skipAccountErrors(processors.NewListFetcher("account", "persons", "/v1/accounts/%s/persons", r.apiClient)),
+			skipAccountErrors(processors.NewListFetcher("account", "capabilities_list", "/v1/accounts/%s/capabilities", r.apiClient)),
+		},
 	}
 	if err := d.Do(ctx, task); err != nil {
 		return err
@@ -69,12 +86,23 @@ func (r *Account) StartProducer(ctx context.Context, runContext integration.RunC
 func (r *Account) StartConsumer(ctx context.Context, ch <-chan api.Object) {
 	defer close(r.msgs)
 	for obj := range ch {
-		if msg := r.transform(obj); msg != nil {
-			r.msgs <- *msg
+		switch tr.GetString(obj, "object") {
+		case "event":
+			if payload := tr.ExtractEventPayload(obj, "account"); payload != nil {
+				r.consumeAccount(payload, true)
+			}
+		case "account":
+			r.consumeAccount(obj, false)
 		}
 	}
 }
 
+func (r *Account) consumeAccount(obj api.Object, fromEvent bool) {
+	if msg := r.transform(obj); msg != nil && !(fromEvent && r.dedupe.SeenBefore(msg.ID)) {
+		r.msgs <- *msg
+	}
+}
+
 func (r *Account) transform(obj api.Object) *source.SetMessage {
 	var id string
 	if id = tr.GetString(obj, "id"); id == "" {
@@ -104,6 +132,8 @@ func (r *Account) transform(obj api.Object) *source.SetMessage {
 
 	tr.Flatten(tr.GetMap(obj, "metadata"), "metadata_", properties)
 	tr.Flatten(tr.GetMap(obj, "support_address"), "support_address_", properties)
+	tr.Flatten(tr.GetMap(obj, "capabilities"), "capabilities_", properties)
+	flattenRequirements(accountRequirements(obj), properties)
 
 	return &source.SetMessage{
 		ID:         id,
@@ -146,3 +176,59 @@ func NewAccount(apiClient api.Client) *Account {
 		dedupe:    dedupe.New(),
 	}
 }
+
+// skipAccountErrors wraps a post-processor that downloads the lists of a connected account. Stripe rejects
+// requests for some accounts (e.g. accounts that were disconnected while being listed), such an account is
+// synced without the list instead of failing the listing of every account.
+func skipAccountErrors(p downloader.PostProcessor) downloader.PostProcessor {
+	return func(ctx context.Context, obj api.Object, task *downloader.Task) error {
+		err := p(ctx, obj, task)
+		stripeErr := api.GetStripeError(err)
+		if stripeErr == nil || !stripeErr.IsPermanent() || stripeErr.IsAuthRelated() {
+			return err
+		}
+
+		log.WithError(err).WithFields(log.Fields{
+			"collection": task.Collection,
+			"account_id": tr.GetString(obj, "id"),
+		}).Warn("skipping a list of a connected account that Stripe rejected")
+		return nil
+	}
+}
+
+// accountRequirements returns the requirements hash of an account, versions before 2019-02-19 have
+// a verification hash instead which is converted to the same shape
+func accountRequirements(obj api.Object) map[string]interface{} {
+	if requirements := tr.GetMap(obj, "requirements"); requirements != nil {
+		return requirements
+	}
+	verification := tr.GetMap(obj, "verification")
+	if verification == nil {
+		return nil
+	}
+	return map[string]interface{}{
+		"currently_due":    verification["fields_needed"],
+		"disabled_reason":  verification["disabled_reason"],
+		"current_deadline": verification["due_by"],
+	}
+}
+
+// flattenRequirements copies the KYC requirements hash of an account, person or capability
+// into requirements_* properties, joining the lists of requirement names with commas
+func flattenRequirements(requirements map[string]interface{}, properties map[string]interface{}) {
+	if requirements == nil {
+		return
+	}
+
+	for _, key := range []string{"currently_due", "eventually_due", "past_due", "pending_verification"} {
+		if _, ok := requirements[key].([]interface{}); ok {
+			properties["requirements_"+key] = strings.Join(tr.GetStringList(requirements, key), ",")
+		}
+	}
+
+	properties["requirements_disabled_reason"] = requirements["disabled_reason"]
+
+	if deadline := tr.GetTimestamp(requirements, "current_deadline"); deadline != "" {
+		properties["requirements_current_deadline"] = deadline
+	}
+}
@@ -0,0 +1,141 @@
+package resource
+
+import (
+	"context"
+	"github.com/segment-sources/stripe/api"
+	"github.com/segment-sources/stripe/integration"
+	"github.com/segment-sources/stripe/resource/dedupe"
+	"github.com/segment-sources/stripe/resource/tr"
+	"github.com/segmentio/go-source"
+	"strings"
+)
+
+var accountCapabilityEvents = []string{
+	"capability.updated",
+}
+
+type AccountCapability struct {
+	name      string
+	apiClient api.Client
+	objs      chan api.Object
+	msgs      chan source.SetMessage
+	errs      chan integration.CollectionError
+	dedupe    dedupe.Interface
+}
+
+func (r *AccountCapability) DesiredObjects() []string {
+	return []string{"account"}
+}
+
+func (r *AccountCapability) DesiredEvents() []string {
+	return accountCapabilityEvents
+}
+
+func (r *AccountCapability) StartProducer(ctx context.Context, runContext integration.RunContext) error {
+	// capabilities are fetched by Account in full sync mode, events are handled by the bundle
+	close(r.objs)
+	close(r.errs)
+	return nil
+}
+
+func (r *AccountCapability) StartConsumer(ctx context.Context, ch <-chan api.Object) {
+	defer close(r.msgs)
+	for obj := range ch {
+		switch tr.GetString(obj, "object") {
+		case "event":
+			if payload := tr.ExtractEventPayload(obj, "capability"); payload != nil {
+				r.consumeCapability(payload, true)
+			}
+		case "account":
+			r.consumeAccount(obj, false)
+		}
+	}
+}
+
+func (r *AccountCapability) consumeCapability(obj api.Object, fromEvent bool) {
+	if msg := r.transform(obj); msg != nil && !(fromEvent && r.dedupe.SeenBefore(msg.ID)) {
+		r.msgs <- *msg
+	}
+}
+
+func (r *AccountCapability) consumeAccount(obj api.Object, fromEvent bool) {
+	for _, capability := range tr.GetMapList(tr.GetMap(obj, "capabilities_list"), "data") {
+		r.consumeCapability(capability, fromEvent)
+	}
+}
+
+func (r *AccountCapability) transform(obj api.Object) *source.SetMessage {
+	var id string
+	if id = makeCapabilityId(obj); id == "" {
+		return nil
+	}
+
+	properties := map[string]interface{}{
+		"account_id": obj["account"],
+		"capability": obj["id"],
+		"requested":  obj["requested"],
+		"status":     obj["status"],
+	}
+
+	flattenRequirements(tr.GetMap(obj, "requirements"), properties)
+
+	if requestedAt := tr.GetTimestamp(obj, "requested_at"); requestedAt != "" {
+		properties["requested_at"] = requestedAt
+	}
+
+	return &source.SetMessage{
+		ID:         id,
+		Collection: r.name,
+		Properties: properties,
+	}
+}
+
+func (r *AccountCapability) Collection() string {
+	return r.name
+}
+
+func (r *AccountCapability) Objects() <-chan api.Object {
+	return r.objs
+}
+
+func (r *AccountCapability) Messages() <-chan source.SetMessage {
+	return r.msgs
+}
+
+func (r *AccountCapability) CollectionErrors() <-chan integration.CollectionError {
+	return r.errs
+}
+
+func (r *AccountCapability) Consumers() []integration.Consumer {
+	return []integration.Consumer{r}
+}
+
+func (r *AccountCapability) Close() {
+	r.dedupe.Close()
+}
+
+func NewAccountCapability(apiClient api.Client) *AccountCapability {
+	return &AccountCapability{
+		name:      "account_capabilities",
+		apiClient: apiClient,
+		objs:      make(chan api.Object, 1000),
+		msgs:      make(chan source.SetMessage),
+		errs:      make(chan integration.CollectionError),
+		dedupe:    dedupe.New(),
+	}
+}
+
+// capability ids such as "card_payments" are only unique within an account
+func makeCapabilityId(capabilityObj map[string]interface{}) string {
+	var accountId string
+	if accountId = tr.GetString(capabilityObj, "account"); accountId == "" {
+		return ""
+	}
+
+	var capabilityId string
+	if capabilityId = tr.GetString(capabilityObj, "id"); capabilityId == "" {
+		return ""
+	}
+
+	return strings.Join([]string{accountId, capabilityId}, "_")
+}
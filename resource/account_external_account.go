@@ -0,0 +1,145 @@
+package resource
+
+import (
+	"context"
+	"github.com/segment-sources/stripe/api"
+	"github.com/segment-sources/stripe/integration"
+	"github.com/segment-sources/stripe/resource/dedupe"
+	"github.com/segment-sources/stripe/resource/downloader"
+	"github.com/segment-sources/stripe/resource/processors"
+	"github.com/segment-sources/stripe/resource/tr"
+	"github.com/segmentio/go-source"
+)
+
+var accountExternalAccountEvents = []string{
+	"account.external_account.created",
+	"account.external_account.deleted",
+	"account.external_account.updated",
+}
+
+type AccountExternalAccount struct {
+	name      string
+	apiClient api.Client
+	objs      chan api.Object
+	msgs      chan source.SetMessage
+	errs      chan integration.CollectionError
+	dedupe    dedupe.Interface
+}
+
+func (r *AccountExternalAccount) DesiredObjects() []string {
+	return []string{"account"}
+}
+
+func (r *AccountExternalAccount) DesiredEvents() []string {
+	return accountExternalAccountEvents
+}
+
+func (r *AccountExternalAccount) StartProducer(ctx context.Context, runContext integration.RunContext) error {
+	// external accounts are embedded in connected accounts, events are handled by the bundle
+	close(r.objs)
+	close(r.errs)
+	return nil
+}
+
+func (r *AccountExternalAccount) GetEventProcessors() []downloader.PostProcessor {
+	return []downloader.PostProcessor{
+		processors.NewIsDeleted("account.external_account.deleted"),
+	}
+}
+
+func (r *AccountExternalAccount) StartConsumer(ctx context.Context, ch <-chan api.Object) {
+	defer close(r.msgs)
+	for obj := range ch {
+		switch tr.GetString(obj, "object") {
+		case "event":
+			if payload := tr.ExtractEventPayload(obj, "bank_account", "card"); payload != nil {
+				r.consumeExternalAccount(payload, true)
+			}
+		case "account":
+			r.consumeAccount(obj, false)
+		}
+	}
+}
+
+func (r *AccountExternalAccount) consumeExternalAccount(obj api.Object, fromEvent bool) {
+	if msg := r.transform(obj); msg != nil && !(fromEvent && r.dedupe.SeenBefore(msg.ID)) {
+		r.msgs <- *msg
+	}
+}
+
+func (r *AccountExternalAccount) consumeAccount(obj api.Object, fromEvent bool) {
+	for _, externalAccount := range tr.GetMapList(tr.GetMap(obj, "external_accounts"), "data") {
+		r.consumeExternalAccount(externalAccount, fromEvent)
+	}
+}
+
+func (r *AccountExternalAccount) transform(obj api.Object) *source.SetMessage {
+	var id string
+	if id = tr.GetString(obj, "id"); id == "" {
+		return nil
+	}
+
+	properties := map[string]interface{}{
+		"account_holder_name":  obj["account_holder_name"],
+		"account_id":           obj["account"],
+		"bank_name":            obj["bank_name"],
+		"brand":                obj["brand"],
+		"country":              obj["country"],
+		"currency":             obj["currency"],
+		"default_for_currency": obj["default_for_currency"],
+		"exp_month":            obj["exp_month"],
+		"exp_year":             obj["exp_year"],
+		"funding":              obj["funding"],
+		"last4":                obj["last4"],
+		"routing_number":       obj["routing_number"],
+		"status":               obj["status"],
+		"type":                 obj["object"],
+	}
+
+	if v, ok := obj["is_deleted"].(bool); ok && v {
+		properties["is_deleted"] = v
+	}
+
+	tr.Flatten(tr.GetMap(obj, "metadata"), "metadata_", properties)
+
+	return &source.SetMessage{
+		ID:         id,
+		Collection: r.name,
+		Properties: properties,
+	}
+}
+
+func (r *AccountExternalAccount) Collection() string {
+	return r.name
+}
+
+func (r *AccountExternalAccount) Objects() <-chan api.Object {
+	return r.objs
+}
+
+func (r *AccountExternalAccount) Messages() <-chan source.SetMessage {
+	return r.msgs
+}
+
+func (r *AccountExternalAccount) CollectionErrors() <-chan integration.CollectionError {
+	return r.errs
+}
+
+func (r *AccountExternalAccount) Consumers() []integration.Consumer {
+	return []integration.Consumer{r}
+}
+
+func (r *AccountExternalAccount) Close() {
+	r.dedupe.Close()
+}
+
+func NewAccountExternalAccount(apiClient api.Client) *AccountExternalAccount {
+	return &AccountExternalAccount{
+		name:      "external_accounts",
+		apiClient: apiClient,
+		objs:      make(chan api.Object, 1000),
+		msgs:      make(chan source.SetMessage),
+		errs:      make(chan integration.CollectionError),
+		dedupe:    dedupe.New(),
+	}
+}
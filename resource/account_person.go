@@ -0,0 +1,146 @@
+package resource
+
+import (
+	"context"
+	"github.com/segment-sources/stripe/api"
+	"github.com/segment-sources/stripe/integration"
+	"github.com/segment-sources/stripe/resource/dedupe"
+	"github.com/segment-sources/stripe/resource/downloader"
+	"github.com/segment-sources/stripe/resource/processors"
+	"github.com/segment-sources/stripe/resource/tr"
+	"github.com/segmentio/go-source"
+)
+
+var accountPersonEvents = []string{
+	"person.created",
+	"person.deleted",
+	"person.updated",
+}
+
+type AccountPerson struct {
+	name      string
+	apiClient api.Client
+	objs      chan api.Object
+	msgs      chan source.SetMessage
+	errs      chan integration.CollectionError
+	dedupe    dedupe.Interface
+}
+
+func (r *AccountPerson) DesiredObjects() []string {
+	return []string{"account"}
+}
+
+func (r *AccountPerson) DesiredEvents() []string {
+	return accountPersonEvents
+}
+
+func (r *AccountPerson) StartProducer(ctx context.Context, runContext integration.RunContext) error {
+	// persons are fetched by Account in full sync mode, events are handled by the bundle
+	close(r.objs)
+	close(r.errs)
+	return nil
+}
+
+func (r *AccountPerson) GetEventProcessors() []downloader.PostProcessor {
+	return []downloader.PostProcessor{
+		processors.NewIsDeleted("person.deleted"),
+	}
+}
+
+func (r *AccountPerson) StartConsumer(ctx context.Context, ch <-chan api.Object) {
+	defer close(r.msgs)
+	for obj := range ch {
+		switch tr.GetString(obj, "object") {
+		case "event":
+			if payload := tr.ExtractEventPayload(obj, "person"); payload != nil {
+				r.consumePerson(payload, true)
+			}
+		case "account":
+			r.consumeAccount(obj, false)
+		}
+	}
+}
+
+func (r *AccountPerson) consumePerson(obj api.Object, fromEvent bool) {
+	if msg := r.transform(obj); msg != nil && !(fromEvent && r.dedupe.SeenBefore(msg.ID)) {
+		r.msgs <- *msg
+	}
+}
+
+func (r *AccountPerson) consumeAccount(obj api.Object, fromEvent bool) {
+	for _, person := range tr.GetMapList(tr.GetMap(obj, "persons"), "data") {
+		r.consumePerson(person, fromEvent)
+	}
+}
+
+func (r *AccountPerson) transform(obj api.Object) *source.SetMessage {
+	var id string
+	if id = tr.GetString(obj, "id"); id == "" {
+		return nil
+	}
+
+	properties := map[string]interface{}{
+		"account_id": obj["account"],
+		"email":      obj["email"],
+		"first_name": obj["first_name"],
+		"last_name":  obj["last_name"],
+		"phone":      obj["phone"],
+	}
+
+	if v, ok := obj["is_deleted"].(bool); ok && v {
+		properties["is_deleted"] = v
+	}
+
+	if verification := tr.GetMap(obj, "verification"); verification != nil {
+		properties["verification_status"] = verification["status"]
+	}
+
+	tr.Flatten(tr.GetMap(obj, "relationship"), "relationship_", properties)
+	tr.Flatten(tr.GetMap(obj, "metadata"), "metadata_", properties)
+	flattenRequirements(tr.GetMap(obj, "requirements"), properties)
+
+	if created := tr.GetTimestamp(obj, "created"); created != "" {
+		properties["created"] = created
+	}
+
+	return &source.SetMessage{
+		ID:         id,
+		Collection: r.name,
+		Properties: properties,
+	}
+}
+
+func (r *AccountPerson) Collection() string {
+	return r.name
+}
+
+func (r *AccountPerson) Objects() <-chan api.Object {
+	return r.objs
+}
+
+func (r *AccountPerson) Messages() <-chan source.SetMessage {
+	return r.msgs
+}
+
+func (r *AccountPerson) CollectionErrors() <-chan integration.CollectionError {
+	return r.errs
+}
+
+func (r *AccountPerson) Consumers() []integration.Consumer {
+	return []integration.Consumer{r}
+}
+
+func (r *AccountPerson) Close() {
+	r.dedupe.Close()
+}
+
+func NewAccountPerson(apiClient api.Client) *AccountPerson {
+	return &AccountPerson{
+		name:      "account_persons",
+		apiClient: apiClient,
+		objs:      make(chan api.Object, 1000),
+		msgs:      make(chan source.SetMessage),
+		errs:      make(chan integration.CollectionError),
+		dedupe:    dedupe.New(),
+	}
+}
@@ -0,0 +1,82 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/segment-sources/stripe/api"
+	"github.com/segment-sources/stripe/integration"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+// connectClient serves the primary account and two connected accounts, requests for the lists
+// of acct_rejected fail with 403
+type connectClient struct {
+	api.Client
+}
+
+func (c *connectClient) GetObject(ctx context.Context, req *api.Request) (api.Object, error) {
+	return api.Object{"id": "acct_primary", "object": "account"}, nil
+}
+
+func (c *connectClient) GetList(ctx context.Context, req *api.Request) (*api.ObjectList, error) {
+	switch {
+	case strings.HasPrefix(req.Url, "/v1/accounts/acct_rejected/"):
+		return nil, &api.StripeError{StatusCode: 403, Type: "invalid_request_error", Code: "account_invalid"}
+	case strings.HasPrefix(req.Url, "/v1/accounts/"):
+		return &api.ObjectList{Objects: []api.Object{{"id": "person_1", "object": "person"}}}, nil
+	}
+	return &api.ObjectList{Objects: []api.Object{
+		{"id": "acct_rejected", "object": "account"},
+		{"id": "acct_connected", "object": "account"},
+	}}, nil
+}
+
+func TestAccountListsOfRejectedAccountsAreSkipped(t *testing.T) {
+	a := assert.New(t)
+	r := NewAccount(&connectClient{})
+	defer r.Close()
+
+	errs := []integration.CollectionError{}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for err := range r.CollectionErrors() {
+			errs = append(errs, err)
+		}
+	}()
+
+	a.NoError(r.StartProducer(context.Background(), integration.RunContext{}))
+	<-done
+	a.Empty(errs)
+
+	accounts := map[string]api.Object{}
+	for obj := range r.Objects() {
+		accounts[obj["id"].(string)] = obj
+	}
+	if a.Len(accounts, 3) {
+		a.Nil(accounts["acct_rejected"]["persons"])
+		a.NotNil(accounts["acct_connected"]["persons"])
+	}
+}
+
+func TestAccountRequirementsOfOlderVersions(t *testing.T) {
+	r := NewAccount(nil)
+	defer r.Close()
+
+	msg := r.transform(api.Object{
+		"id":     "acct_1",
+		"object": "account",
+		"verification": map[string]interface{}{
+			"disabled_reason": "fields_needed",
+			"due_by":          json.Number("1562112000"),
+			"fields_needed":   []interface{}{"legal_entity.dob.day", "legal_entity.dob.month"},
+		},
+	})
+
+	a := assert.New(t)
+	a.Equal("fields_needed", msg.Properties["requirements_disabled_reason"])
+	a.Equal("2019-07-03T00:00:00.000Z", msg.Properties["requirements_current_deadline"])
+	a.Equal("legal_entity.dob.day,legal_entity.dob.month", msg.Properties["requirements_currently_due"])
+}
@@ -33,26 +33,37 @@ func (r *ApplicationFee) DesiredEvents() []string {
 	return applicationFeeEvents
 }
 
+func (r *ApplicationFee) ListEndpoint() string {
+	return "/v1/application_fees"
+}
+
+func (r *ApplicationFee) ListTask() *downloader.Task {
+	return &downloader.Task{
+		Collection: r.name,
+		Request: &api.Request{
+			Url:           "/v1/application_fees?limit=100",
+			LogCollection: r.name,
+		},
+		Output: r.objs,
+		Errors: r.errs,
+		PostProcessors: []downloader.PostProcessor{
+			processors.NewListExpander("refunds", r.apiClient),
+		},
+	}
+}
+
 func (r *ApplicationFee) StartProducer(ctx context.Context, runContext integration.RunContext) error {
 	defer close(r.objs)
 	defer close(r.errs)
 	if runContext.PreviousRunTimestamp.IsZero() {
-		return downloader.New(r.apiClient).Do(ctx, &downloader.Task{
-			Collection: r.name,
-			Request: &api.Request{
-				Url:           "/v1/application_fees?limit=100",
-				LogCollection: r.name,
-			},
-			Output: r.objs,
-			Errors: r.errs,
-			PostProcessors: []downloader.PostProcessor{
-				processors.NewListExpander("refunds", r.apiClient),
-			},
-		})
+		if !runContext.StaleRunTimestamp.IsZero() {
+			return partialResync(ctx, r.apiClient, r, r.ListTask(), runContext.StaleRunTimestamp)
+		}
+		return downloader.New(r.apiClient).Do(ctx, r.ListTask())
 	}
 
 	// downloading events in incremental mode is handled by the bundle that this resource is a part of
-	return nil
+	return backfill(ctx, r.apiClient, r.ListTask(), runContext)
 }
 
 func (r *ApplicationFee) StartConsumer(ctx context.Context, ch <-chan api.Object) {
@@ -86,8 +97,8 @@ func (r *ApplicationFee) transform(obj api.Object) *source.SetMessage {
 		"amount":                  obj["amount"],
 		"amount_refunded":         obj["amount_refunded"],
 		"application_id":          obj["application"],
-		"balance_transaction_id":  obj["balance_transaction"],
-		"charge_id":               obj["charge"],
+		"balance_transaction_id":  tr.GetId(obj, "balance_transaction"),
+		"charge_id":               tr.GetId(obj, "charge"),
 		"currency":                obj["currency"],
 		"originating_transaction": obj["originating_transaction"],
 		"refunded":                obj["refunded"],
@@ -0,0 +1,26 @@
+package resource
+
+import (
+	"context"
+	"github.com/apex/log"
+	"github.com/segment-sources/stripe/api"
+	"github.com/segment-sources/stripe/integration"
+	"github.com/segment-sources/stripe/resource/downloader"
+	"github.com/segment-sources/stripe/resource/tasks"
+)
+
+// backfill downloads objects of the list task's collection that were created in the backfill's time range,
+// it does nothing unless the collection was selected for a backfill of an incremental run
+func backfill(ctx context.Context, apiClient api.Client, listTask *downloader.Task, runContext integration.RunContext) error {
+	b := runContext.Backfill
+	if runContext.PreviousRunTimestamp.IsZero() || !b.Start(listTask.Collection) {
+		return nil
+	}
+
+	log.WithFields(log.Fields{
+		"collection": listTask.Collection,
+		"from":       b.From,
+		"to":         b.To,
+	}).Info("backfilling collection")
+	return downloader.New(apiClient).Do(ctx, tasks.CreatedBetween(listTask, b.From, b.To))
+}
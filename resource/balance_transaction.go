@@ -9,6 +9,7 @@ import (
 	"github.com/segment-sources/stripe/resource/tr"
 	"github.com/segmentio/go-source"
 	"net/url"
+	"sync"
 	"time"
 )
 
@@ -19,6 +20,8 @@ type BalanceTransaction struct {
 	msgs              chan source.SetMessage
 	errs              chan integration.CollectionError
 	enableTransferIds bool
+	// skipChargeTransactions is set if charges are listed together with their balance transactions
+	skipChargeTransactions bool
 }
 
 func (r *BalanceTransaction) DesiredObjects() []string {
@@ -29,6 +32,10 @@ func (r *BalanceTransaction) DesiredEvents() []string {
 	return nil
 }
 
+func (r *BalanceTransaction) ListEndpoint() string {
+	return "/v1/balance/history"
+}
+
 func (r *BalanceTransaction) StartProducer(ctx context.Context, runContext integration.RunContext) error {
 	defer close(r.objs)
 	defer close(r.errs)
@@ -51,6 +58,11 @@ func (r *BalanceTransaction) StartProducer(ctx context.Context, runContext integ
 		req.Qs.Set("created[gt]", fmt.Sprintf("%d", timestampLimit.Unix()))
 	}
 
+	// a full sync of charges downloads their balance transactions already
+	if r.skipChargeTransactions && runContext.PreviousRunTimestamp.IsZero() && runContext.StaleRunTimestamp.IsZero() {
+		return r.listWithoutCharges(ctx, req)
+	}
+
 	return downloader.New(r.apiClient).Do(ctx, &downloader.Task{
 		Collection: r.name,
 		Request:    req,
@@ -59,6 +71,40 @@ func (r *BalanceTransaction) StartProducer(ctx context.Context, runContext integ
 	})
 }
 
+// listWithoutCharges lists the balance history leaving out the transactions of charges and payments,
+// the endpoint can only be filtered by a single type
+func (r *BalanceTransaction) listWithoutCharges(ctx context.Context, req *api.Request) error {
+	ch := make(chan api.Object)
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for obj := range ch {
+			if txType := tr.GetString(obj, "type"); txType != "charge" && txType != "payment" {
+				r.objs <- obj
+			}
+		}
+	}()
+
+	err := downloader.New(r.apiClient).Do(ctx, &downloader.Task{
+		Collection: r.name,
+		Request:    req,
+		Output:     ch,
+		Errors:     r.errs,
+	})
+
+	close(ch)
+	wg.Wait()
+
+	return err
+}
+
+// SkipChargeTransactions makes full syncs leave out the balance transactions of charges, it's used
+// if the charges of the sync are downloaded together with their balance transactions
+func (r *BalanceTransaction) SkipChargeTransactions() {
+	r.skipChargeTransactions = true
+}
+
 func (r *BalanceTransaction) StartConsumer(ctx context.Context, ch <-chan api.Object) {
 	defer close(r.msgs)
 	for obj := range ch {
@@ -89,7 +135,7 @@ func (r *BalanceTransaction) transform(obj api.Object) *source.SetMessage {
 		"net":         obj["net"],
 		"status":      obj["status"],
 		"type":        obj["type"],
-		"source":      obj["source"],
+		"source":      tr.GetId(obj, "source"),
 	}
 
 	// transferId is set by RelatedTransactions processor in "transfers" resource if this option is enabled
@@ -0,0 +1,65 @@
+package resource
+
+import (
+	"context"
+	"github.com/segment-sources/stripe/api"
+	"github.com/segment-sources/stripe/integration"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// historyClient serves a single page of the balance history
+type historyClient struct {
+	api.Client
+}
+
+func (c *historyClient) GetList(ctx context.Context, req *api.Request) (*api.ObjectList, error) {
+	return &api.ObjectList{Objects: []api.Object{
+		{"id": "txn_1", "object": "balance_transaction", "type": "charge"},
+		{"id": "txn_2", "object": "balance_transaction", "type": "refund"},
+		{"id": "txn_3", "object": "balance_transaction", "type": "payment"},
+		{"id": "txn_4", "object": "balance_transaction", "type": "payout"},
+	}}, nil
+}
+
+func listBalanceTransactions(t *testing.T, runContext integration.RunContext) []string {
+	r := NewBalanceTransaction(&historyClient{}, false)
+	r.SkipChargeTransactions()
+	defer r.Close()
+
+	go func() {
+		for range r.CollectionErrors() {
+		}
+	}()
+	assert.NoError(t, r.StartProducer(context.Background(), runContext))
+
+	ids := []string{}
+	for obj := range r.Objects() {
+		ids = append(ids, obj["id"].(string))
+	}
+	return ids
+}
+
+func TestFullSyncSkipsTransactionsOfCharges(t *testing.T) {
+	assert.Equal(t, []string{"txn_2", "txn_4"}, listBalanceTransactions(t, integration.RunContext{}))
+}
+
+func TestIncrementalSyncListsTransactionsOfCharges(t *testing.T) {
+	ids := listBalanceTransactions(t, integration.RunContext{PreviousRunTimestamp: time.Now().Add(-time.Hour)})
+	assert.Equal(t, []string{"txn_1", "txn_2", "txn_3", "txn_4"}, ids)
+}
+
+func TestChargesExpandBalanceTransactionsIfPermitted(t *testing.T) {
+	a := assert.New(t)
+
+	expanding := NewCharge(nil, 0, false, true)
+	defer expanding.Close()
+	a.Equal([]string{"data.balance_transaction"}, expanding.ListTask().Expand)
+	a.Len(expanding.ListTask().PostProcessors, 1)
+
+	plain := NewCharge(nil, 0, false, false)
+	defer plain.Close()
+	a.Empty(plain.ListTask().Expand)
+	a.Empty(plain.ListTask().PostProcessors)
+}
@@ -34,7 +34,7 @@ func (r *BankAccount) DesiredObjects() []string {
 }
 
 func (r *BankAccount) DesiredEvents() []string {
-	return append(bankAccountEvents, chargeEvents...)
+	return append(append([]string{"customer.deleted"}, bankAccountEvents...), chargeEvents...)
 }
 
 func (r *BankAccount) StartProducer(ctx context.Context, runContext integration.RunContext) error {
@@ -46,7 +46,7 @@ func (r *BankAccount) StartProducer(ctx context.Context, runContext integration.
 
 func (r *BankAccount) GetEventProcessors() []downloader.PostProcessor {
 	return []downloader.PostProcessor{
-		processors.NewIsDeleted("customer.source.deleted", "account.external_account.deleted"),
+		processors.NewIsDeleted("customer.source.deleted", "account.external_account.deleted", "customer.deleted"),
 	}
 }
 
@@ -55,12 +55,14 @@ func (r *BankAccount) StartConsumer(ctx context.Context, ch <-chan api.Object) {
 	for obj := range ch {
 		switch tr.GetString(obj, "object") {
 		case "event":
-			if payload := tr.ExtractEventPayload(obj, "bank_account", "charge"); payload != nil {
+			if payload := tr.ExtractEventPayload(obj, "bank_account", "charge", "customer"); payload != nil {
 				switch tr.GetString(payload, "object") {
 				case "bank_account":
-					r.consumeBankAccount(payload, true)
+					r.consumeBankAccount(payload, true, false)
 				case "charge":
 					r.consumeCharge(payload, true)
+				case "customer":
+					r.consumeCustomer(payload, true)
 				}
 			}
 		case "charge":
@@ -71,8 +73,11 @@ func (r *BankAccount) StartConsumer(ctx context.Context, ch <-chan api.Object) {
 	}
 }
 
-func (r *BankAccount) consumeBankAccount(obj api.Object, fromEvent bool) {
+func (r *BankAccount) consumeBankAccount(obj api.Object, fromEvent bool, customerDeleted bool) {
 	if msg := r.transform(obj); msg != nil && !(fromEvent && r.dedupe.SeenBefore(msg.ID)) {
+		if customerDeleted {
+			msg.Properties["is_deleted"] = true
+		}
 		r.msgs <- *msg
 	}
 }
@@ -80,14 +85,15 @@ func (r *BankAccount) consumeBankAccount(obj api.Object, fromEvent bool) {
 func (r *BankAccount) consumeCharge(obj api.Object, fromEvent bool) {
 	src := tr.GetMap(obj, "source")
 	if src != nil && tr.GetString(src, "object") == "bank_account" {
-		r.consumeBankAccount(src, fromEvent)
+		r.consumeBankAccount(src, fromEvent, false)
 	}
 }
 
 func (r *BankAccount) consumeCustomer(obj api.Object, fromEvent bool) {
+	deleted := tr.GetBool(obj, "is_deleted")
 	for _, src := range tr.GetMapList(tr.GetMap(obj, "sources"), "data") {
 		if tr.GetString(src, "object") == "bank_account" {
-			r.consumeBankAccount(src, fromEvent)
+			r.consumeBankAccount(src, fromEvent, deleted)
 		}
 	}
 }
@@ -11,6 +11,7 @@ import (
 	"github.com/segmentio/ur-log"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 // ResourceBundle is a group of resources that behaves like resource itself.
@@ -21,6 +22,8 @@ type ResourceBundle struct {
 	errs           chan integration.CollectionError
 	resources      []integration.Resource
 	producerErrors int32
+	// processorWorkers post-process events concurrently, events are still consumed in order
+	processorWorkers int
 }
 
 func (b *ResourceBundle) forwardProducer(ctx context.Context, res integration.Resource, runContext integration.RunContext) error {
@@ -40,13 +43,17 @@ func (b *ResourceBundle) forwardProducer(ctx context.Context, res integration.Re
 		}
 	}()
 
+	// members are scheduled separately so that a dependency of one member doesn't delay the others
+	ctx, finish := integration.StartScheduled(ctx, res)
+	defer finish()
+
 	err := res.StartProducer(ctx, runContext)
 	wg.Wait()
 	return err
 }
 
-// eventsProducer downloads a joined stream of events desired by all member's consumers
-func (b *ResourceBundle) eventsProducer(ctx context.Context, runContext integration.RunContext) error {
+// eventsProducer downloads a joined stream of events desired by all member's consumers since the timestamp
+func (b *ResourceBundle) eventsProducer(ctx context.Context, since time.Time) error {
 	consumers := b.Consumers()
 
 	collectionNames := []string{}
@@ -72,13 +79,12 @@ func (b *ResourceBundle) eventsProducer(ctx context.Context, runContext integrat
 		}
 	}()
 
-	err := downloader.New(b.apiClient).Do(ctx, tasks.MakeIncremental(
-		b,
-		"events",
-		runContext.PreviousRunTimestamp,
-		b.objs,
-		colErrors,
-	))
+	// there are no events to download if every member that desires events was disabled
+	var err error
+	if task := tasks.MakeIncremental(b, "events", since, b.objs, colErrors); task != nil {
+		task.ProcessorWorkers = b.processorWorkers
+		err = downloader.New(b.apiClient).Do(ctx, task)
+	}
 
 	close(colErrors)
 	wg.Wait()
@@ -87,12 +93,20 @@ func (b *ResourceBundle) eventsProducer(ctx context.Context, runContext integrat
 }
 
 // StartProducer starts every member resources' producer and a special event producer
-// that downloads all member resources' events in a single stream
+// that downloads all member resources' events in a single stream. If the run context is stale,
+// the retained events are replayed for every member, since members that are derived from other
+// objects can't be listed by the time they were created.
 func (b *ResourceBundle) StartProducer(ctx context.Context, runContext integration.RunContext) error {
 	defer close(b.objs)
 	defer close(b.errs)
 	wg := sync.WaitGroup{}
 
+	since := runContext.PreviousRunTimestamp
+	if since.IsZero() && !runContext.StaleRunTimestamp.IsZero() {
+		since = tasks.RetainedSince(runContext.StaleRunTimestamp)
+	}
+	ctx = tasks.WithBundledEvents(ctx)
+
 	// start forwarding producers
 	for _, res := range b.resources {
 		wg.Add(1)
@@ -105,12 +119,12 @@ func (b *ResourceBundle) StartProducer(ctx context.Context, runContext integrati
 		}(res)
 	}
 
-	// if incremental mode enabled, start event producer
-	if !runContext.PreviousRunTimestamp.IsZero() {
+	// if incremental mode enabled or the context is stale, start event producer
+	if !since.IsZero() {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			if err := b.eventsProducer(ctx, runContext); err != nil {
+			if err := b.eventsProducer(ctx, since); err != nil {
 				log.WithError(err).Error("bundle event producer failed")
 				atomic.AddInt32(&b.producerErrors, 1)
 			}
@@ -133,6 +147,17 @@ func (b *ResourceBundle) CollectionErrors() <-chan integration.CollectionError {
 	return b.errs
 }
 
+// Schedule gives the bundle's joined stream of events the highest priority of its members
+func (b *ResourceBundle) Schedule() integration.Schedule {
+	schedule := integration.Schedule{Priority: 1}
+	for _, res := range b.resources {
+		if i, ok := res.(integration.HasSchedule); ok && i.Schedule().Priority > schedule.Priority {
+			schedule.Priority = i.Schedule().Priority
+		}
+	}
+	return schedule
+}
+
 // Consumers returns a joint list of all member resource's event processors
 func (b *ResourceBundle) GetEventProcessors() []downloader.PostProcessor {
 	postProcessors := []downloader.PostProcessor{}
@@ -144,6 +169,20 @@ func (b *ResourceBundle) GetEventProcessors() []downloader.PostProcessor {
 	return postProcessors
 }
 
+// PartialCollections returns the collections of members whose full sync doesn't list every object,
+// they aren't reconciled
+func (b *ResourceBundle) PartialCollections() []string {
+	collections := []string{}
+	for _, res := range b.resources {
+		if i, ok := res.(integration.PartialListing); ok && i.PartialListing() {
+			for _, con := range res.Consumers() {
+				collections = append(collections, con.Collection())
+			}
+		}
+	}
+	return collections
+}
+
 // Consumers returns a joint list of all member resource's consumers
 func (b *ResourceBundle) Consumers() []integration.Consumer {
 	result := []integration.Consumer{}
@@ -161,11 +200,12 @@ func (b *ResourceBundle) Close() {
 	}
 }
 
-func New(apiClient api.Client, resources ...integration.Resource) *ResourceBundle {
+func New(apiClient api.Client, processorWorkers int, resources ...integration.Resource) *ResourceBundle {
 	return &ResourceBundle{
-		apiClient: apiClient,
-		objs:      make(chan api.Object, 1000),
-		errs:      make(chan integration.CollectionError),
-		resources: resources,
+		apiClient:        apiClient,
+		objs:             make(chan api.Object, 1000),
+		errs:             make(chan integration.CollectionError),
+		resources:        resources,
+		processorWorkers: processorWorkers,
 	}
 }
@@ -31,7 +31,7 @@ func (r *Card) DesiredObjects() []string {
 }
 
 func (r *Card) DesiredEvents() []string {
-	return append(cardEvents, chargeEvents...)
+	return append(append([]string{"customer.deleted"}, cardEvents...), chargeEvents...)
 }
 
 func (r *Card) StartProducer(ctx context.Context, runContext integration.RunContext) error {
@@ -43,7 +43,7 @@ func (r *Card) StartProducer(ctx context.Context, runContext integration.RunCont
 
 func (r *Card) GetEventProcessors() []downloader.PostProcessor {
 	return []downloader.PostProcessor{
-		processors.NewIsDeleted("customer.source.deleted"),
+		processors.NewIsDeleted("customer.source.deleted", "customer.deleted"),
 	}
 }
 
@@ -52,12 +52,14 @@ func (r *Card) StartConsumer(ctx context.Context, ch <-chan api.Object) {
 	for obj := range ch {
 		switch tr.GetString(obj, "object") {
 		case "event":
-			if payload := tr.ExtractEventPayload(obj, "card", "charge"); payload != nil {
+			if payload := tr.ExtractEventPayload(obj, "card", "charge", "customer"); payload != nil {
 				switch tr.GetString(payload, "object") {
 				case "card":
-					r.consumeCard(payload, true)
+					r.consumeCard(payload, true, false)
 				case "charge":
 					r.consumeCharge(payload, true)
+				case "customer":
+					r.consumeCustomer(payload, true)
 				}
 			}
 		case "charge":
@@ -68,8 +70,11 @@ func (r *Card) StartConsumer(ctx context.Context, ch <-chan api.Object) {
 	}
 }
 
-func (r *Card) consumeCard(obj api.Object, fromEvent bool) {
+func (r *Card) consumeCard(obj api.Object, fromEvent bool, customerDeleted bool) {
 	if msg := r.transform(obj); msg != nil && !(fromEvent && r.dedupe.SeenBefore(msg.ID)) {
+		if customerDeleted {
+			msg.Properties["is_deleted"] = true
+		}
 		r.msgs <- *msg
 	}
 }
@@ -77,14 +82,15 @@ func (r *Card) consumeCard(obj api.Object, fromEvent bool) {
 func (r *Card) consumeCharge(obj api.Object, fromEvent bool) {
 	src := tr.GetMap(obj, "source")
 	if src != nil && tr.GetString(src, "object") == "card" {
-		r.consumeCard(src, fromEvent)
+		r.consumeCard(src, fromEvent, false)
 	}
 }
 
 func (r *Card) consumeCustomer(obj api.Object, fromEvent bool) {
+	deleted := tr.GetBool(obj, "is_deleted")
 	for _, src := range tr.GetMapList(tr.GetMap(obj, "sources"), "data") {
 		if tr.GetString(src, "object") == "card" {
-			r.consumeCard(src, fromEvent)
+			r.consumeCard(src, fromEvent, deleted)
 		}
 	}
 }
@@ -118,7 +124,7 @@ func (r *Card) transform(obj api.Object) *source.SetMessage {
 	}
 
 	if obj["customer"] != nil {
-		properties["customer_id"] = obj["customer"]
+		properties["customer_id"] = tr.GetId(obj, "customer")
 	}
 
 	if v, ok := obj["is_deleted"].(bool); ok && v {
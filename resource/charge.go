@@ -6,6 +6,7 @@ import (
 	"github.com/segment-sources/stripe/integration"
 	"github.com/segment-sources/stripe/resource/dedupe"
 	"github.com/segment-sources/stripe/resource/downloader"
+	"github.com/segment-sources/stripe/resource/processors"
 	"github.com/segment-sources/stripe/resource/tr"
 	"github.com/segmentio/go-source"
 )
@@ -26,6 +27,10 @@ type Charge struct {
 	msgs      chan source.SetMessage
 	errs      chan integration.CollectionError
 	dedupe    dedupe.Interface
+	slicing   *downloader.Slicing
+	search    bool
+	// expandBalanceTransactions is only set if the API key may read balance transactions
+	expandBalanceTransactions bool
 }
 
 func (r *Charge) DesiredObjects() []string {
@@ -36,23 +41,48 @@ func (r *Charge) DesiredEvents() []string {
 	return chargeEvents
 }
 
+func (r *Charge) ListEndpoint() string {
+	return "/v1/charges"
+}
+
+func (r *Charge) ListTask() *downloader.Task {
+	task := &downloader.Task{
+		Collection: r.name,
+		Request: &api.Request{
+			Url:           "/v1/charges?limit=100",
+			LogCollection: r.name,
+		},
+		Output:  r.objs,
+		Errors:  r.errs,
+		Slicing: r.slicing,
+	}
+	if r.expandBalanceTransactions {
+		// balance transactions are consumed along with the charges, so that their fee details are
+		// captured without waiting for the balance history to be downloaded
+		task.Expand = []string{"data.balance_transaction"}
+		task.PostProcessors = []downloader.PostProcessor{
+			processors.NewExpandedObjects("balance_transaction"),
+		}
+	}
+	return task
+}
+
 func (r *Charge) StartProducer(ctx context.Context, runContext integration.RunContext) error {
 	defer close(r.objs)
 	defer close(r.errs)
 	if runContext.PreviousRunTimestamp.IsZero() {
-		return downloader.New(r.apiClient).Do(ctx, &downloader.Task{
-			Collection: r.name,
-			Request: &api.Request{
-				Url:           "/v1/charges?limit=100",
-				LogCollection: r.name,
-			},
-			Output: r.objs,
-			Errors: r.errs,
-		})
+		task := r.ListTask()
+		if r.search && !runContext.StaleRunTimestamp.IsZero() {
+			return searchIncremental(ctx, r.apiClient, r, task, "/v1/charges/search", runContext.StaleRunTimestamp)
+		}
+		if !runContext.StaleRunTimestamp.IsZero() {
+			return partialResync(ctx, r.apiClient, r, task, runContext.StaleRunTimestamp)
+		}
+		return downloader.New(r.apiClient).Do(ctx, task)
 	}
 
 	// downloading events in incremental mode is handled by the bundle that this resource is a part of
-	return nil
+	return backfill(ctx, r.apiClient, r.ListTask(), runContext)
 }
 
 func (r *Charge) StartConsumer(ctx context.Context, ch <-chan api.Object) {
@@ -84,32 +114,42 @@ func (r *Charge) transform(obj api.Object) *source.SetMessage {
 	properties := map[string]interface{}{
 		"amount":                 obj["amount"],
 		"amount_refunded":        obj["amount_refunded"],
-		"application_fee":        obj["application_fee"],
-		"balance_transaction_id": obj["balance_transaction"],
+		"application_fee":        tr.GetId(obj, "application_fee"),
+		"balance_transaction_id": tr.GetId(obj, "balance_transaction"),
 		"captured":               obj["captured"],
 		"currency":               obj["currency"],
-		"customer_id":            obj["customer"],
+		"customer_id":            tr.GetId(obj, "customer"),
 		"description":            obj["description"],
-		"destination":            obj["destination"],
+		"destination":            tr.GetId(obj, "destination"),
 		"failure_code":           obj["failure_code"],
 		"failure_message":        obj["failure_message"],
-		"invoice_id":             obj["invoice"],
+		"invoice_id":             tr.GetId(obj, "invoice"),
 		"paid":                   obj["paid"],
 		"receipt_email":          obj["receipt_email"],
 		"receipt_number":         obj["receipt_number"],
 		"refunded":               obj["refunded"],
+		"review_id":              tr.GetId(obj, "review"),
 		"statement_descriptor":   obj["statement_descriptor"],
 		"status":                 obj["status"],
 	}
 
 	tr.Flatten(tr.GetMap(obj, "metadata"), "metadata_", properties)
 	tr.Flatten(tr.GetMap(obj, "fraud_details"), "fraud_details_", properties)
+	tr.Flatten(tr.GetMap(obj, "outcome"), "outcome_", properties)
 	tr.Flatten(tr.GetMap(obj, "shipping"), "shipping_", properties)
 
 	if created := tr.GetTimestamp(obj, "created"); created != "" {
 		properties["created"] = created
 	}
 
+	if details := tr.GetMap(obj, "payment_method_details"); details != nil {
+		if cardPresent := tr.GetMap(details, "card_present"); cardPresent != nil {
+			tr.Flatten(cardPresent, "card_present_", properties)
+			delete(properties, "card_present_reader")
+			properties["terminal_reader_id"] = cardPresent["reader"]
+		}
+	}
+
 	if src := tr.GetMap(obj, "source"); src != nil {
 		srcId := tr.GetString(src, "id")
 		if srcType := tr.GetString(src, "object"); srcType == "card" {
@@ -150,13 +190,19 @@ func (r *Charge) Close() {
 	r.dedupe.Close()
 }
 
-func NewCharge(apiClient api.Client) *Charge {
+// NewCharge downloads time windows of a full sync concurrently if fullSyncWorkers is more than one.
+// If search is set, objects created since a stale previous run are searched for instead of a full sync.
+// If expandBalanceTransactions is set, listed charges are downloaded together with their balance transactions.
+func NewCharge(apiClient api.Client, fullSyncWorkers int, search bool, expandBalanceTransactions bool) *Charge {
 	return &Charge{
-		name:      "charges",
-		apiClient: apiClient,
-		objs:      make(chan api.Object, 1000),
-		msgs:      make(chan source.SetMessage),
-		errs:      make(chan integration.CollectionError),
-		dedupe:    dedupe.New(),
+		name:                      "charges",
+		apiClient:                 apiClient,
+		objs:                      make(chan api.Object, 1000),
+		msgs:                      make(chan source.SetMessage),
+		errs:                      make(chan integration.CollectionError),
+		dedupe:                    dedupe.New(),
+		slicing:                   downloader.NewSlicing(fullSyncWorkers),
+		search:                    search,
+		expandBalanceTransactions: expandBalanceTransactions,
 	}
 }
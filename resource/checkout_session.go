@@ -0,0 +1,174 @@
+package resource
+
+import (
+	"context"
+	"github.com/segment-sources/stripe/api"
+	"github.com/segment-sources/stripe/integration"
+	"github.com/segment-sources/stripe/resource/dedupe"
+	"github.com/segment-sources/stripe/resource/downloader"
+	"github.com/segment-sources/stripe/resource/processors"
+	"github.com/segment-sources/stripe/resource/tr"
+	"github.com/segmentio/go-source"
+)
+
+var checkoutSessionEvents = []string{
+	"checkout.session.async_payment_failed",
+	"checkout.session.async_payment_succeeded",
+	"checkout.session.completed",
+	"checkout.session.expired",
+}
+
+type CheckoutSession struct {
+	name      string
+	apiClient api.Client
+	objs      chan api.Object
+	msgs      chan source.SetMessage
+	errs      chan integration.CollectionError
+	dedupe    dedupe.Interface
+}
+
+func (r *CheckoutSession) DesiredObjects() []string {
+	return []string{"checkout.session"}
+}
+
+func (r *CheckoutSession) DesiredEvents() []string {
+	return checkoutSessionEvents
+}
+
+func (r *CheckoutSession) ListEndpoint() string {
+	return "/v1/checkout/sessions"
+}
+
+func (r *CheckoutSession) ListTask() *downloader.Task {
+	return &downloader.Task{
+		Collection: r.name,
+		Request: &api.Request{
+			Url:           "/v1/checkout/sessions?limit=100",
+			LogCollection: r.name,
+		},
+		Output: r.objs,
+		Errors: r.errs,
+		PostProcessors: []downloader.PostProcessor{
+			r.newLineItemsFetcher(),
+		},
+	}
+}
+
+func (r *CheckoutSession) StartProducer(ctx context.Context, runContext integration.RunContext) error {
+	defer close(r.objs)
+	defer close(r.errs)
+	if runContext.PreviousRunTimestamp.IsZero() {
+		if !runContext.StaleRunTimestamp.IsZero() {
+			return partialResync(ctx, r.apiClient, r, r.ListTask(), runContext.StaleRunTimestamp)
+		}
+		return downloader.New(r.apiClient).Do(ctx, r.ListTask())
+	}
+
+	// downloading events in incremental mode is handled by the bundle that this resource is a part of
+	return backfill(ctx, r.apiClient, r.ListTask(), runContext)
+}
+
+func (r *CheckoutSession) GetEventProcessors() []downloader.PostProcessor {
+	return []downloader.PostProcessor{
+		r.newLineItemsFetcher(),
+	}
+}
+
+// line items aren't embedded in checkout sessions and have to be requested for every session separately
+func (r *CheckoutSession) newLineItemsFetcher() downloader.PostProcessor {
+	return processors.NewListFetcher("checkout.session", "line_items", "/v1/checkout/sessions/%s/line_items", r.apiClient)
+}
+
+func (r *CheckoutSession) StartConsumer(ctx context.Context, ch <-chan api.Object) {
+	defer close(r.msgs)
+	for obj := range ch {
+		switch tr.GetString(obj, "object") {
+		case "event":
+			if payload := tr.ExtractEventPayload(obj, "checkout.session"); payload != nil {
+				r.consumeSession(payload, true)
+			}
+		case "checkout.session":
+			r.consumeSession(obj, false)
+		}
+	}
+}
+
+func (r *CheckoutSession) consumeSession(obj api.Object, fromEvent bool) {
+	if msg := r.transform(obj); msg != nil && !(fromEvent && r.dedupe.SeenBefore(msg.ID)) {
+		r.msgs <- *msg
+	}
+}
+
+func (r *CheckoutSession) transform(obj api.Object) *source.SetMessage {
+	var id string
+	if id = tr.GetString(obj, "id"); id == "" {
+		return nil
+	}
+
+	properties := map[string]interface{}{
+		"amount_subtotal":     obj["amount_subtotal"],
+		"amount_total":        obj["amount_total"],
+		"client_reference_id": obj["client_reference_id"],
+		"currency":            obj["currency"],
+		"customer_email":      obj["customer_email"],
+		"customer_id":         tr.GetId(obj, "customer"),
+		"invoice_id":          tr.GetId(obj, "invoice"),
+		"mode":                obj["mode"],
+		"payment_intent_id":   tr.GetId(obj, "payment_intent"),
+		"payment_link_id":     obj["payment_link"],
+		"payment_status":      obj["payment_status"],
+		"status":              obj["status"],
+		"subscription_id":     tr.GetId(obj, "subscription"),
+	}
+
+	tr.Flatten(tr.GetMap(obj, "customer_details"), "customer_details_", properties)
+	tr.Flatten(tr.GetMap(obj, "metadata"), "metadata_", properties)
+
+	if created := tr.GetTimestamp(obj, "created"); created != "" {
+		properties["created"] = created
+	}
+	if expiresAt := tr.GetTimestamp(obj, "expires_at"); expiresAt != "" {
+		properties["expires_at"] = expiresAt
+	}
+
+	return &source.SetMessage{
+		ID:         id,
+		Collection: r.name,
+		Properties: properties,
+	}
+}
+
+func (r *CheckoutSession) Collection() string {
+	return r.name
+}
+
+func (r *CheckoutSession) Objects() <-chan api.Object {
+	return r.objs
+}
+
+func (r *CheckoutSession) Messages() <-chan source.SetMessage {
+	return r.msgs
+}
+
+func (r *CheckoutSession) CollectionErrors() <-chan integration.CollectionError {
+	return r.errs
+}
+
+func (r *CheckoutSession) Consumers() []integration.Consumer {
+	return []integration.Consumer{r}
+}
+
+func (r *CheckoutSession) Close() {
+	r.dedupe.Close()
+}
+
+func NewCheckoutSession(apiClient api.Client) *CheckoutSession {
+	return &CheckoutSession{
+		name:      "checkout_sessions",
+		apiClient: apiClient,
+		objs:      make(chan api.Object, 1000),
+		msgs:      make(chan source.SetMessage),
+		errs:      make(chan integration.CollectionError),
+		dedupe:    dedupe.New(),
+	}
+}
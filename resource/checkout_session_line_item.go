@@ -0,0 +1,133 @@
+package resource
+
+import (
+	"context"
+	"github.com/segment-sources/stripe/api"
+	"github.com/segment-sources/stripe/integration"
+	"github.com/segment-sources/stripe/resource/dedupe"
+	"github.com/segment-sources/stripe/resource/tr"
+	"github.com/segmentio/go-source"
+)
+
+type CheckoutSessionLineItem struct {
+	name      string
+	apiClient api.Client
+	objs      chan api.Object
+	msgs      chan source.SetMessage
+	errs      chan integration.CollectionError
+	dedupe    dedupe.Interface
+}
+
+func (r *CheckoutSessionLineItem) DesiredObjects() []string {
+	return []string{"checkout.session"}
+}
+
+func (r *CheckoutSessionLineItem) DesiredEvents() []string {
+	return checkoutSessionEvents
+}
+
+func (r *CheckoutSessionLineItem) StartProducer(ctx context.Context, runContext integration.RunContext) error {
+	// downloading events in incremental mode is handled by the bundle that this resource is a part of
+	close(r.objs)
+	close(r.errs)
+	return nil
+}
+
+func (r *CheckoutSessionLineItem) StartConsumer(ctx context.Context, ch <-chan api.Object) {
+	defer close(r.msgs)
+	for obj := range ch {
+		switch tr.GetString(obj, "object") {
+		case "event":
+			if payload := tr.ExtractEventPayload(obj, "checkout.session"); payload != nil {
+				r.consumeSession(payload, true)
+			}
+		case "checkout.session":
+			r.consumeSession(obj, false)
+		}
+	}
+}
+
+func (r *CheckoutSessionLineItem) consumeSession(obj api.Object, fromEvent bool) {
+	var sessionId string
+	if sessionId = tr.GetString(obj, "id"); sessionId == "" || fromEvent && r.dedupe.SeenBefore(sessionId) {
+		return
+	}
+
+	for _, item := range tr.GetMapList(tr.GetMap(obj, "line_items"), "data") {
+		if msg := r.transform(obj, item); msg != nil {
+			r.msgs <- *msg
+		}
+	}
+}
+
+func (r *CheckoutSessionLineItem) transform(session, item api.Object) *source.SetMessage {
+	var itemId string
+	if itemId = tr.GetString(item, "id"); itemId == "" {
+		return nil
+	}
+	var sessionId string
+	if sessionId = tr.GetString(session, "id"); sessionId == "" {
+		return nil
+	}
+
+	properties := map[string]interface{}{
+		"amount_discount":     item["amount_discount"],
+		"amount_subtotal":     item["amount_subtotal"],
+		"amount_tax":          item["amount_tax"],
+		"amount_total":        item["amount_total"],
+		"checkout_session_id": sessionId,
+		"currency":            item["currency"],
+		"description":         item["description"],
+		"quantity":            item["quantity"],
+	}
+
+	if price := tr.GetMap(item, "price"); price != nil {
+		if priceId := tr.GetString(price, "id"); priceId != "" {
+			properties["price_id"] = priceId
+		}
+		if productId := tr.GetString(price, "product"); productId != "" {
+			properties["product_id"] = productId
+		}
+	}
+
+	return &source.SetMessage{
+		ID:         itemId,
+		Collection: r.name,
+		Properties: properties,
+	}
+}
+
+func (r *CheckoutSessionLineItem) Collection() string {
+	return r.name
+}
+
+func (r *CheckoutSessionLineItem) Objects() <-chan api.Object {
+	return r.objs
+}
+
+func (r *CheckoutSessionLineItem) Messages() <-chan source.SetMessage {
+	return r.msgs
+}
+
+func (r *CheckoutSessionLineItem) CollectionErrors() <-chan integration.CollectionError {
+	return r.errs
+}
+
+func (r *CheckoutSessionLineItem) Consumers() []integration.Consumer {
+	return []integration.Consumer{r}
+}
+
+func (r *CheckoutSessionLineItem) Close() {
+	r.dedupe.Close()
+}
+
+func NewCheckoutSessionLineItem(apiClient api.Client) *CheckoutSessionLineItem {
+	return &CheckoutSessionLineItem{
+		name:      "checkout_session_line_items",
+		apiClient: apiClient,
+		objs:      make(chan api.Object, 1000),
+		msgs:      make(chan source.SetMessage),
+		errs:      make(chan integration.CollectionError),
+		dedupe:    dedupe.New(),
+	}
+}
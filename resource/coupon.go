@@ -38,23 +38,38 @@ func (r *Coupon) DesiredEvents() []string {
 	return allEventTypes
 }
 
+func (r *Coupon) ListEndpoint() string {
+	return "/v1/coupons"
+}
+
+func (r *Coupon) Schedule() integration.Schedule {
+	return integration.Schedule{Priority: referencePriority}
+}
+
+func (r *Coupon) ListTask() *downloader.Task {
+	return &downloader.Task{
+		Collection: r.name,
+		Request: &api.Request{
+			Url:           "/v1/coupons?limit=100",
+			LogCollection: r.name,
+		},
+		Output: r.objs,
+		Errors: r.errs,
+	}
+}
+
 func (r *Coupon) StartProducer(ctx context.Context, runContext integration.RunContext) error {
 	defer close(r.objs)
 	defer close(r.errs)
 	if runContext.PreviousRunTimestamp.IsZero() {
-		return downloader.New(r.apiClient).Do(ctx, &downloader.Task{
-			Collection: r.name,
-			Request: &api.Request{
-				Url:           "/v1/coupons?limit=100",
-				LogCollection: r.name,
-			},
-			Output: r.objs,
-			Errors: r.errs,
-		})
+		if !runContext.StaleRunTimestamp.IsZero() {
+			return partialResync(ctx, r.apiClient, r, r.ListTask(), runContext.StaleRunTimestamp)
+		}
+		return downloader.New(r.apiClient).Do(ctx, r.ListTask())
 	}
 
 	// downloading events in incremental mode is handled by the bundle that this resource is a part of
-	return nil
+	return backfill(ctx, r.apiClient, r.ListTask(), runContext)
 }
 
 func (r *Coupon) GetEventProcessors() []downloader.PostProcessor {
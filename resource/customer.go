@@ -7,7 +7,6 @@ import (
 	"github.com/segment-sources/stripe/resource/dedupe"
 	"github.com/segment-sources/stripe/resource/downloader"
 	"github.com/segment-sources/stripe/resource/processors"
-	"github.com/segment-sources/stripe/resource/tasks"
 	"github.com/segment-sources/stripe/resource/tr"
 	"github.com/segmentio/go-source"
 )
@@ -25,6 +24,10 @@ type Customer struct {
 	msgs      chan source.SetMessage
 	errs      chan integration.CollectionError
 	dedupe    dedupe.Interface
+	slicing   *downloader.Slicing
+	search    bool
+	// cashBalances is set if customer cash balance transactions are synced
+	cashBalances bool
 }
 
 func (r *Customer) DesiredObjects() []string {
@@ -35,33 +38,59 @@ func (r *Customer) DesiredEvents() []string {
 	return customerEvents
 }
 
+func (r *Customer) ListEndpoint() string {
+	return "/v1/customers"
+}
+
+func (r *Customer) Schedule() integration.Schedule {
+	return integration.Schedule{Priority: dimensionPriority}
+}
+
+func (r *Customer) ListTask() *downloader.Task {
+	task := &downloader.Task{
+		Collection: r.name,
+		Request: &api.Request{
+			Url:           "/v1/customers?limit=100",
+			LogCollection: r.name,
+		},
+		Output:  r.objs,
+		Errors:  r.errs,
+		Slicing: r.slicing,
+		PostProcessors: []downloader.PostProcessor{
+			processors.NewListExpander("sources", r.apiClient),
+			newCustomerPaymentMethodsFetcher(r.apiClient),
+			newCustomerBalanceTransactionsFetcher(r.apiClient),
+			newCustomerTaxIdsFetcher(r.apiClient),
+		},
+	}
+	if r.cashBalances {
+		task.PostProcessors = append(task.PostProcessors, newCustomerCashBalanceTransactionsFetcher(r.apiClient))
+	}
+	return task
+}
+
 func (r *Customer) StartProducer(ctx context.Context, runContext integration.RunContext) error {
 	defer close(r.objs)
 	defer close(r.errs)
-	var task *downloader.Task
 	if runContext.PreviousRunTimestamp.IsZero() {
-		task = &downloader.Task{
-			Collection: r.name,
-			Request: &api.Request{
-				Url:           "/v1/customers?limit=100",
-				LogCollection: r.name,
-			},
-			Output: r.objs,
-			Errors: r.errs,
-			PostProcessors: []downloader.PostProcessor{
-				processors.NewListExpander("sources", r.apiClient),
-			},
+		task := r.ListTask()
+		if r.search && !runContext.StaleRunTimestamp.IsZero() {
+			return searchIncremental(ctx, r.apiClient, r, task, "/v1/customers/search", runContext.StaleRunTimestamp)
+		}
+		if !runContext.StaleRunTimestamp.IsZero() {
+			return partialResync(ctx, r.apiClient, r, task, runContext.StaleRunTimestamp)
 		}
-	} else {
-		task = tasks.MakeIncremental(r, r.name, runContext.PreviousRunTimestamp, r.objs, r.errs)
+		return downloader.New(r.apiClient).Do(ctx, task)
 	}
 
-	return downloader.New(r.apiClient).Do(ctx, task)
+	// downloading events in incremental mode is handled by the bundle that this resource is a part of
+	return backfill(ctx, r.apiClient, r.ListTask(), runContext)
 }
 
 func (r *Customer) GetEventProcessors() []downloader.PostProcessor {
 	return []downloader.PostProcessor{
 		processors.NewIsDeleted("customer.deleted"),
+		newCustomerPaymentMethodsFetcher(r.apiClient),
 	}
 }
 
@@ -93,6 +122,7 @@ func (r *Customer) transform(obj api.Object) *source.SetMessage {
 
 	properties := map[string]interface{}{
 		"account_balance": obj["account_balance"],
+		"balance":         obj["balance"],
 		"currency":        obj["currency"],
 		"delinquent":      obj["delinquent"],
 		"description":     obj["description"],
@@ -140,13 +170,39 @@ func (r *Customer) Close() {
 	r.dedupe.Close()
 }
 
-func NewCustomer(apiClient api.Client) *Customer {
+// NewCustomer downloads time windows of a full sync concurrently if fullSyncWorkers is more than one.
+// If search is set, objects created since a stale previous run are searched for instead of a full sync.
+// If cashBalances is set, cash balance transactions of customers are downloaded in full sync mode.
+func NewCustomer(apiClient api.Client, fullSyncWorkers int, search bool, cashBalances bool) *Customer {
 	return &Customer{
-		name:      "customers",
-		apiClient: apiClient,
-		objs:      make(chan api.Object, 1000),
-		msgs:      make(chan source.SetMessage),
-		errs:      make(chan integration.CollectionError),
-		dedupe:    dedupe.New(),
+		name:         "customers",
+		apiClient:    apiClient,
+		objs:         make(chan api.Object, 1000),
+		msgs:         make(chan source.SetMessage),
+		errs:         make(chan integration.CollectionError),
+		dedupe:       dedupe.New(),
+		slicing:      downloader.NewSlicing(fullSyncWorkers),
+		search:       search,
+		cashBalances: cashBalances,
+	}
+}
+
+// newCustomerPaymentMethodsFetcher lists cards of customers as payment methods if the API version
+// doesn't embed sources in customers anymore, the version adapter converts them to card sources.
+// Both customers and customer event payloads are processed.
+func newCustomerPaymentMethodsFetcher(apiClient api.Client) downloader.PostProcessor {
+	fetcher := processors.NewListFetcher("customer", "sources", "/v1/customers/%s/payment_methods?type=card", apiClient)
+	return func(ctx context.Context, obj api.Object, task *downloader.Task) error {
+		target := obj
+		if tr.GetString(obj, "object") == "event" {
+			target = tr.ExtractEventPayload(obj)
+		}
+		if target == nil {
+			return nil
+		}
+		if _, ok := target["sources"]; ok {
+			return nil
+		}
+		return fetcher(ctx, obj, task)
 	}
 }
@@ -0,0 +1,178 @@
+package resource
+
+import (
+	"context"
+	"github.com/segment-sources/stripe/api"
+	"github.com/segment-sources/stripe/integration"
+	"github.com/segment-sources/stripe/resource/dedupe"
+	"github.com/segment-sources/stripe/resource/downloader"
+	"github.com/segment-sources/stripe/resource/processors"
+	"github.com/segment-sources/stripe/resource/tr"
+	"github.com/segmentio/go-source"
+	"sync"
+)
+
+var customerBalanceTransactionEvents = []string{
+	"customer.created",
+	"customer.updated",
+}
+
+type CustomerBalanceTransaction struct {
+	name      string
+	apiClient api.Client
+	objs      chan api.Object
+	msgs      chan source.SetMessage
+	errs      chan integration.CollectionError
+	dedupe    dedupe.Interface
+}
+
+func (r *CustomerBalanceTransaction) DesiredObjects() []string {
+	return []string{"customer"}
+}
+
+func (r *CustomerBalanceTransaction) DesiredEvents() []string {
+	return customerBalanceTransactionEvents
+}
+
+func (r *CustomerBalanceTransaction) StartProducer(ctx context.Context, runContext integration.RunContext) error {
+	// balance transactions are fetched by Customer in full sync mode, events are handled by the bundle
+	close(r.objs)
+	close(r.errs)
+	return nil
+}
+
+func (r *CustomerBalanceTransaction) GetEventProcessors() []downloader.PostProcessor {
+	return []downloader.PostProcessor{
+		newCustomerBalanceTransactionsEventFetcher(r.apiClient),
+	}
+}
+
+func (r *CustomerBalanceTransaction) StartConsumer(ctx context.Context, ch <-chan api.Object) {
+	defer close(r.msgs)
+	for obj := range ch {
+		switch tr.GetString(obj, "object") {
+		case "event":
+			if payload := tr.ExtractEventPayload(obj, "customer"); payload != nil {
+				r.consumeCustomer(payload, true)
+			}
+		case "customer":
+			r.consumeCustomer(obj, false)
+		}
+	}
+}
+
+func (r *CustomerBalanceTransaction) consumeBalanceTransaction(obj api.Object, fromEvent bool) {
+	if msg := r.transform(obj); msg != nil && !(fromEvent && r.dedupe.SeenBefore(msg.ID)) {
+		r.msgs <- *msg
+	}
+}
+
+func (r *CustomerBalanceTransaction) consumeCustomer(obj api.Object, fromEvent bool) {
+	for _, item := range tr.GetMapList(tr.GetMap(obj, "balance_transactions"), "data") {
+		r.consumeBalanceTransaction(item, fromEvent)
+	}
+}
+
+func (r *CustomerBalanceTransaction) transform(obj api.Object) *source.SetMessage {
+	var id string
+	if id = tr.GetString(obj, "id"); id == "" {
+		return nil
+	}
+
+	properties := map[string]interface{}{
+		"amount":         obj["amount"],
+		"credit_note_id": obj["credit_note"],
+		"currency":       obj["currency"],
+		"customer_id":    tr.GetId(obj, "customer"),
+		"description":    obj["description"],
+		"ending_balance": obj["ending_balance"],
+		"invoice_id":     tr.GetId(obj, "invoice"),
+		"type":           obj["type"],
+	}
+
+	tr.Flatten(tr.GetMap(obj, "metadata"), "metadata_", properties)
+
+	if created := tr.GetTimestamp(obj, "created"); created != "" {
+		properties["created"] = created
+	}
+
+	return &source.SetMessage{
+		ID:         id,
+		Collection: r.name,
+		Properties: properties,
+	}
+}
+
+func (r *CustomerBalanceTransaction) Collection() string {
+	return r.name
+}
+
+func (r *CustomerBalanceTransaction) Objects() <-chan api.Object {
+	return r.objs
+}
+
+func (r *CustomerBalanceTransaction) Messages() <-chan source.SetMessage {
+	return r.msgs
+}
+
+func (r *CustomerBalanceTransaction) CollectionErrors() <-chan integration.CollectionError {
+	return r.errs
+}
+
+func (r *CustomerBalanceTransaction) Consumers() []integration.Consumer {
+	return []integration.Consumer{r}
+}
+
+func (r *CustomerBalanceTransaction) Close() {
+	r.dedupe.Close()
+}
+
+func NewCustomerBalanceTransaction(apiClient api.Client) *CustomerBalanceTransaction {
+	return &CustomerBalanceTransaction{
+		name:      "customer_balance_transactions",
+		apiClient: apiClient,
+		objs:      make(chan api.Object, 1000),
+		msgs:      make(chan source.SetMessage),
+		errs:      make(chan integration.CollectionError),
+		dedupe:    dedupe.New(),
+	}
+}
+
+// balance transactions don't have events of their own, instead every customer update
+// triggers a download of the customer's balance transactions
+func newCustomerBalanceTransactionsFetcher(apiClient api.Client) downloader.PostProcessor {
+	return processors.NewListFetcher("customer", "balance_transactions", "/v1/customers/%s/balance_transactions", apiClient)
+}
+
+// newCustomerBalanceTransactionsEventFetcher downloads the balance transactions of a customer once per run,
+// a customer usually has many events in a run and a single download already lists every transaction.
+// The processors are created for every run, so customers updated in later runs are downloaded again.
+func newCustomerBalanceTransactionsEventFetcher(apiClient api.Client) downloader.PostProcessor {
+	fetcher := newCustomerBalanceTransactionsFetcher(apiClient)
+	mu := sync.Mutex{}
+	fetched := map[string]bool{}
+
+	return func(ctx context.Context, obj api.Object, task *downloader.Task) error {
+		customerId := tr.GetString(tr.ExtractEventPayload(obj, "customer"), "id")
+		if customerId == "" {
+			return nil
+		}
+
+		mu.Lock()
+		seen := fetched[customerId]
+		fetched[customerId] = true
+		mu.Unlock()
+		if seen {
+			return nil
+		}
+
+		err := fetcher(ctx, obj, task)
+		if err != nil {
+			// failed downloads aren't remembered, the customer is downloaded again if it's processed again
+			mu.Lock()
+			delete(fetched, customerId)
+			mu.Unlock()
+		}
+		return err
+	}
+}
@@ -0,0 +1,50 @@
+package resource
+
+import (
+	"context"
+	"github.com/segment-sources/stripe/api"
+	"github.com/segment-sources/stripe/resource/downloader"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func customerEvent(eventType string, customerId string) api.Object {
+	return api.Object{
+		"object": "event",
+		"type":   eventType,
+		"data": map[string]interface{}{
+			"object": map[string]interface{}{"id": customerId, "object": "customer"},
+		},
+	}
+}
+
+func TestBalanceTransactionsAreFetchedOncePerCustomer(t *testing.T) {
+	client := &requestClient{}
+	r := NewCustomerBalanceTransaction(client)
+	defer r.Close()
+
+	processors := r.GetEventProcessors()
+	task := &downloader.Task{Collection: "customer_balance_transactions"}
+	for _, event := range []api.Object{
+		customerEvent("customer.updated", "cus_1"),
+		customerEvent("customer.updated", "cus_2"),
+		customerEvent("customer.updated", "cus_1"),
+		customerEvent("customer.created", "cus_1"),
+	} {
+		for _, processor := range processors {
+			assert.NoError(t, processor(context.Background(), event, task))
+		}
+	}
+
+	urls := []string{}
+	for _, req := range client.requests {
+		urls = append(urls, req.Url)
+	}
+	assert.Equal(t, []string{"/v1/customers/cus_1/balance_transactions", "/v1/customers/cus_2/balance_transactions"}, urls)
+
+	// processors of the next run download the customers again
+	for _, processor := range r.GetEventProcessors() {
+		assert.NoError(t, processor(context.Background(), customerEvent("customer.updated", "cus_1"), task))
+	}
+	assert.Len(t, client.requests, 3)
+}
@@ -0,0 +1,152 @@
+package resource
+
+import (
+	"context"
+	"github.com/segment-sources/stripe/api"
+	"github.com/segment-sources/stripe/integration"
+	"github.com/segment-sources/stripe/resource/dedupe"
+	"github.com/segment-sources/stripe/resource/downloader"
+	"github.com/segment-sources/stripe/resource/processors"
+	"github.com/segment-sources/stripe/resource/tr"
+	"github.com/segmentio/go-source"
+)
+
+var customerCashBalanceTransactionEvents = []string{
+	"customer_cash_balance_transaction.created",
+}
+
+type CustomerCashBalanceTransaction struct {
+	name      string
+	apiClient api.Client
+	objs      chan api.Object
+	msgs      chan source.SetMessage
+	errs      chan integration.CollectionError
+	dedupe    dedupe.Interface
+}
+
+func (r *CustomerCashBalanceTransaction) DesiredObjects() []string {
+	return []string{"customer"}
+}
+
+func (r *CustomerCashBalanceTransaction) DesiredEvents() []string {
+	return customerCashBalanceTransactionEvents
+}
+
+func (r *CustomerCashBalanceTransaction) StartProducer(ctx context.Context, runContext integration.RunContext) error {
+	// cash balance transactions are fetched by Customer in full sync mode, events are handled by the bundle
+	close(r.objs)
+	close(r.errs)
+	return nil
+}
+
+func (r *CustomerCashBalanceTransaction) StartConsumer(ctx context.Context, ch <-chan api.Object) {
+	defer close(r.msgs)
+	for obj := range ch {
+		switch tr.GetString(obj, "object") {
+		case "event":
+			if payload := tr.ExtractEventPayload(obj, "customer_cash_balance_transaction"); payload != nil {
+				r.consumeTransaction(payload, true)
+			}
+		case "customer":
+			r.consumeCustomer(obj, false)
+		}
+	}
+}
+
+func (r *CustomerCashBalanceTransaction) consumeTransaction(obj api.Object, fromEvent bool) {
+	if msg := r.transform(obj); msg != nil && !(fromEvent && r.dedupe.SeenBefore(msg.ID)) {
+		r.msgs <- *msg
+	}
+}
+
+func (r *CustomerCashBalanceTransaction) consumeCustomer(obj api.Object, fromEvent bool) {
+	for _, item := range tr.GetMapList(tr.GetMap(obj, "cash_balance_transactions"), "data") {
+		r.consumeTransaction(item, fromEvent)
+	}
+}
+
+func (r *CustomerCashBalanceTransaction) transform(obj api.Object) *source.SetMessage {
+	var id string
+	if id = tr.GetString(obj, "id"); id == "" {
+		return nil
+	}
+
+	properties := map[string]interface{}{
+		"currency":       obj["currency"],
+		"customer_id":    tr.GetId(obj, "customer"),
+		"ending_balance": obj["ending_balance"],
+		"net_amount":     obj["net_amount"],
+		"type":           obj["type"],
+	}
+
+	// the details of a transaction are kept in a hash named after its type
+	if v := tr.GetMap(obj, "applied_to_payment"); v != nil {
+		properties["payment_intent_id"] = tr.GetId(v, "payment_intent")
+	}
+	if v := tr.GetMap(obj, "unapplied_from_payment"); v != nil {
+		properties["payment_intent_id"] = tr.GetId(v, "payment_intent")
+	}
+	if v := tr.GetMap(obj, "refunded_from_payment"); v != nil {
+		properties["refund_id"] = tr.GetId(v, "refund")
+	}
+	if v := tr.GetMap(obj, "transferred_to_balance"); v != nil {
+		properties["balance_transaction_id"] = tr.GetId(v, "balance_transaction")
+	}
+	if v := tr.GetMap(obj, "adjusted_for_overdraft"); v != nil {
+		properties["balance_transaction_id"] = tr.GetId(v, "balance_transaction")
+	}
+	if v := tr.GetMap(tr.GetMap(obj, "funded"), "bank_transfer"); v != nil {
+		properties["bank_transfer_type"] = v["type"]
+		properties["bank_transfer_reference"] = v["reference"]
+	}
+
+	if created := tr.GetTimestamp(obj, "created"); created != "" {
+		properties["created"] = created
+	}
+
+	return &source.SetMessage{
+		ID:         id,
+		Collection: r.name,
+		Properties: properties,
+	}
+}
+
+func (r *CustomerCashBalanceTransaction) Collection() string {
+	return r.name
+}
+
+func (r *CustomerCashBalanceTransaction) Objects() <-chan api.Object {
+	return r.objs
+}
+
+func (r *CustomerCashBalanceTransaction) Messages() <-chan source.SetMessage {
+	return r.msgs
+}
+
+func (r *CustomerCashBalanceTransaction) CollectionErrors() <-chan integration.CollectionError {
+	return r.errs
+}
+
+func (r *CustomerCashBalanceTransaction) Consumers() []integration.Consumer {
+	return []integration.Consumer{r}
+}
+
+func (r *CustomerCashBalanceTransaction) Close() {
+	r.dedupe.Close()
+}
+
+func NewCustomerCashBalanceTransaction(apiClient api.Client) *CustomerCashBalanceTransaction {
+	return &CustomerCashBalanceTransaction{
+		name:      "customer_cash_balance_transactions",
+		apiClient: apiClient,
+		objs:      make(chan api.Object, 1000),
+		msgs:      make(chan source.SetMessage),
+		errs:      make(chan integration.CollectionError),
+		dedupe:    dedupe.New(),
+	}
+}
+
+// cash balance transactions are only downloaded in full sync mode, later ones are received from their events
+func newCustomerCashBalanceTransactionsFetcher(apiClient api.Client) downloader.PostProcessor {
+	return processors.NewListFetcher("customer", "cash_balance_transactions", "/v1/customers/%s/cash_balance_transactions", apiClient)
+}
@@ -0,0 +1,65 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/segment-sources/stripe/api"
+	"github.com/segmentio/go-source"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCustomerCashBalanceTransactionConsumer(t *testing.T) {
+	r := NewCustomerCashBalanceTransaction(nil)
+	defer r.Close()
+
+	transaction := map[string]interface{}{
+		"id":     "ccsbtxn_1",
+		"object": "customer_cash_balance_transaction",
+		"applied_to_payment": map[string]interface{}{
+			"payment_intent": "pi_1",
+		},
+		"created":        json.Number("1656633600"),
+		"currency":       "eur",
+		"customer":       "cus_1",
+		"ending_balance": json.Number("0"),
+		"net_amount":     json.Number("-1000"),
+		"type":           "applied_to_payment",
+	}
+	ch := make(chan api.Object, 2)
+	ch <- api.Object{
+		"id":     "cus_1",
+		"object": "customer",
+		"cash_balance_transactions": map[string]interface{}{
+			"object": "list",
+			"data":   []interface{}{transaction},
+		},
+	}
+	ch <- api.Object{
+		"object": "event",
+		"type":   "customer_cash_balance_transaction.created",
+		"data":   map[string]interface{}{"object": transaction},
+	}
+	close(ch)
+	go r.StartConsumer(context.Background(), ch)
+
+	expected := source.SetMessage{
+		ID:         "ccsbtxn_1",
+		Collection: "customer_cash_balance_transactions",
+		Properties: map[string]interface{}{
+			"created":           "2022-07-01T00:00:00.000Z",
+			"currency":          "eur",
+			"customer_id":       "cus_1",
+			"ending_balance":    json.Number("0"),
+			"net_amount":        json.Number("-1000"),
+			"payment_intent_id": "pi_1",
+			"type":              "applied_to_payment",
+		},
+	}
+	msgs := []source.SetMessage{}
+	for msg := range r.Messages() {
+		msgs = append(msgs, msg)
+	}
+	// the transaction of the event is sent again, only a second event of it would be deduplicated
+	assert.Equal(t, []source.SetMessage{expected, expected}, msgs)
+}
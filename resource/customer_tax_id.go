@@ -0,0 +1,145 @@
+package resource
+
+import (
+	"context"
+	"github.com/segment-sources/stripe/api"
+	"github.com/segment-sources/stripe/integration"
+	"github.com/segment-sources/stripe/resource/dedupe"
+	"github.com/segment-sources/stripe/resource/downloader"
+	"github.com/segment-sources/stripe/resource/processors"
+	"github.com/segment-sources/stripe/resource/tr"
+	"github.com/segmentio/go-source"
+)
+
+var customerTaxIdEvents = []string{
+	"customer.tax_id.created",
+	"customer.tax_id.deleted",
+	"customer.tax_id.updated",
+}
+
+type CustomerTaxId struct {
+	name      string
+	apiClient api.Client
+	objs      chan api.Object
+	msgs      chan source.SetMessage
+	errs      chan integration.CollectionError
+	dedupe    dedupe.Interface
+}
+
+func (r *CustomerTaxId) DesiredObjects() []string {
+	return []string{"customer"}
+}
+
+func (r *CustomerTaxId) DesiredEvents() []string {
+	return customerTaxIdEvents
+}
+
+func (r *CustomerTaxId) StartProducer(ctx context.Context, runContext integration.RunContext) error {
+	// tax ids are fetched by Customer in full sync mode, events are handled by the bundle
+	close(r.objs)
+	close(r.errs)
+	return nil
+}
+
+func (r *CustomerTaxId) GetEventProcessors() []downloader.PostProcessor {
+	return []downloader.PostProcessor{
+		processors.NewIsDeleted("customer.tax_id.deleted"),
+	}
+}
+
+func (r *CustomerTaxId) StartConsumer(ctx context.Context, ch <-chan api.Object) {
+	defer close(r.msgs)
+	for obj := range ch {
+		switch tr.GetString(obj, "object") {
+		case "event":
+			if payload := tr.ExtractEventPayload(obj, "tax_id"); payload != nil {
+				r.consumeTaxId(payload, true)
+			}
+		case "customer":
+			r.consumeCustomer(obj, false)
+		}
+	}
+}
+
+func (r *CustomerTaxId) consumeTaxId(obj api.Object, fromEvent bool) {
+	if msg := r.transform(obj); msg != nil && !(fromEvent && r.dedupe.SeenBefore(msg.ID)) {
+		r.msgs <- *msg
+	}
+}
+
+func (r *CustomerTaxId) consumeCustomer(obj api.Object, fromEvent bool) {
+	for _, item := range tr.GetMapList(tr.GetMap(obj, "tax_ids"), "data") {
+		r.consumeTaxId(item, fromEvent)
+	}
+}
+
+func (r *CustomerTaxId) transform(obj api.Object) *source.SetMessage {
+	var id string
+	if id = tr.GetString(obj, "id"); id == "" {
+		return nil
+	}
+
+	properties := map[string]interface{}{
+		"country":     obj["country"],
+		"customer_id": tr.GetId(obj, "customer"),
+		"type":        obj["type"],
+		"value":       obj["value"],
+	}
+
+	if v, ok := obj["is_deleted"].(bool); ok && v {
+		properties["is_deleted"] = v
+	}
+
+	if verification := tr.GetMap(obj, "verification"); verification != nil {
+		properties["verification_status"] = verification["status"]
+	}
+
+	if created := tr.GetTimestamp(obj, "created"); created != "" {
+		properties["created"] = created
+	}
+
+	return &source.SetMessage{
+		ID:         id,
+		Collection: r.name,
+		Properties: properties,
+	}
+}
+
+func (r *CustomerTaxId) Collection() string {
+	return r.name
+}
+
+func (r *CustomerTaxId) Objects() <-chan api.Object {
+	return r.objs
+}
+
+func (r *CustomerTaxId) Messages() <-chan source.SetMessage {
+	return r.msgs
+}
+
+func (r *CustomerTaxId) CollectionErrors() <-chan integration.CollectionError {
+	return r.errs
+}
+
+func (r *CustomerTaxId) Consumers() []integration.Consumer {
+	return []integration.Consumer{r}
+}
+
+func (r *CustomerTaxId) Close() {
+	r.dedupe.Close()
+}
+
+func NewCustomerTaxId(apiClient api.Client) *CustomerTaxId {
+	return &CustomerTaxId{
+		name:      "customer_tax_ids",
+		apiClient: apiClient,
+		objs:      make(chan api.Object, 1000),
+		msgs:      make(chan source.SetMessage),
+		errs:      make(chan integration.CollectionError),
+		dedupe:    dedupe.New(),
+	}
+}
+
+func newCustomerTaxIdsFetcher(apiClient api.Client) downloader.PostProcessor {
+	return processors.NewListFetcher("customer", "tax_ids", "/v1/customers/%s/tax_ids", apiClient)
+}
@@ -5,6 +5,8 @@ import (
 	"github.com/segment-sources/stripe/api"
 	"github.com/segment-sources/stripe/integration"
 	"github.com/segment-sources/stripe/resource/dedupe"
+	"github.com/segment-sources/stripe/resource/downloader"
+	"github.com/segment-sources/stripe/resource/processors"
 	"github.com/segment-sources/stripe/resource/tr"
 	"github.com/segmentio/go-source"
 	"strings"
@@ -30,7 +32,7 @@ func (r *Discount) DesiredObjects() []string {
 }
 
 func (r *Discount) DesiredEvents() []string {
-	allEventTypes := discountEvents
+	allEventTypes := append([]string{"customer.deleted"}, discountEvents...)
 	allEventTypes = append(allEventTypes, invoiceEvents...)
 	allEventTypes = append(allEventTypes, subscriptionEvents...)
 	return allEventTypes
@@ -43,15 +45,24 @@ func (r *Discount) StartProducer(ctx context.Context, runContext integration.Run
 	return nil
 }
 
+// GetEventProcessors marks deleted discounts and customers as deleted, discounts of a deleted customer are deleted too
+func (r *Discount) GetEventProcessors() []downloader.PostProcessor {
+	return []downloader.PostProcessor{
+		processors.NewIsDeleted("customer.discount.deleted", "customer.deleted"),
+	}
+}
+
 func (r *Discount) StartConsumer(ctx context.Context, ch <-chan api.Object) {
 	defer close(r.msgs)
 	for obj := range ch {
 		switch tr.GetString(obj, "object") {
 		case "event":
-			if payload := tr.ExtractEventPayload(obj, "discount", "invoice", "subscription"); payload != nil {
+			if payload := tr.ExtractEventPayload(obj, "discount", "customer", "invoice", "subscription"); payload != nil {
 				switch tr.GetString(payload, "object") {
 				case "discount":
-					r.consumeDiscount(payload, true)
+					r.consumeDiscount(payload, true, false)
+				case "customer":
+					r.consumeParent(payload, true)
 				case "invoice":
 					r.consumeParent(payload, true)
 				case "subscription":
@@ -68,15 +79,21 @@ func (r *Discount) StartConsumer(ctx context.Context, ch <-chan api.Object) {
 	}
 }
 
-func (r *Discount) consumeDiscount(obj api.Object, fromEvent bool) {
+func (r *Discount) consumeDiscount(obj api.Object, fromEvent bool, parentDeleted bool) {
 	if msg := r.transform(obj); msg != nil && !(fromEvent && r.dedupe.SeenBefore(msg.ID)) {
+		if parentDeleted {
+			msg.Properties["is_deleted"] = true
+		}
 		r.msgs <- *msg
 	}
 }
 
 func (r *Discount) consumeParent(obj api.Object, fromEvent bool) {
 	if discount := tr.GetMap(obj, "discount"); discount != nil {
-		r.consumeDiscount(discount, fromEvent)
+		// a cancelled subscription's discount isn't deleted, discounts are identified by the customer
+		// and the coupon, so it may be the same as the customer's discount
+		deleted := tr.GetString(obj, "object") == "customer" && tr.GetBool(obj, "is_deleted")
+		r.consumeDiscount(discount, fromEvent, deleted)
 	}
 }
 
@@ -87,8 +104,8 @@ func (r *Discount) transform(obj api.Object) *source.SetMessage {
 	}
 
 	properties := map[string]interface{}{
-		"customer_id":  obj["customer"],
-		"subscription": obj["subscription"],
+		"customer_id":  tr.GetId(obj, "customer"),
+		"subscription": tr.GetId(obj, "subscription"),
 	}
 
 	if v := tr.GetTimestamp(obj, "start"); v != "" {
@@ -103,6 +120,10 @@ func (r *Discount) transform(obj api.Object) *source.SetMessage {
 		properties["coupon_id"] = m["id"]
 	}
 
+	if v, ok := obj["is_deleted"].(bool); ok && v {
+		properties["is_deleted"] = v
+	}
+
 	return &source.SetMessage{
 		ID:         id,
 		Collection: r.name,
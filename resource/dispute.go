@@ -36,25 +36,39 @@ func (r *Dispute) DesiredEvents() []string {
 	return disputeEvents
 }
 
+func (r *Dispute) ListEndpoint() string {
+	return "/v1/disputes"
+}
+
+func (r *Dispute) ListTask() *downloader.Task {
+	return &downloader.Task{
+		Collection: r.name,
+		Request: &api.Request{
+			Url:           "/v1/disputes?limit=100",
+			LogCollection: r.name,
+		},
+		Output: r.objs,
+		Errors: r.errs,
+	}
+}
+
 func (r *Dispute) StartProducer(ctx context.Context, runContext integration.RunContext) error {
 	defer close(r.objs)
 	defer close(r.errs)
 	var task *downloader.Task
 	if runContext.PreviousRunTimestamp.IsZero() {
-		task = &downloader.Task{
-			Collection: r.name,
-			Request: &api.Request{
-				Url:           "/v1/disputes?limit=100",
-				LogCollection: r.name,
-			},
-			Output: r.objs,
-			Errors: r.errs,
+		task = r.ListTask()
+		if !runContext.StaleRunTimestamp.IsZero() {
+			return partialResync(ctx, r.apiClient, r, task, runContext.StaleRunTimestamp)
 		}
 	} else {
 		task = tasks.MakeIncremental(r, r.name, runContext.PreviousRunTimestamp, r.objs, r.errs)
 	}
 
-	return downloader.New(r.apiClient).Do(ctx, task)
+	if err := downloader.New(r.apiClient).Do(ctx, task); err != nil {
+		return err
+	}
+	return backfill(ctx, r.apiClient, r.ListTask(), runContext)
 }
 
 func (r *Dispute) StartConsumer(ctx context.Context, ch <-chan api.Object) {
@@ -85,7 +99,7 @@ func (r *Dispute) transform(obj api.Object) *source.SetMessage {
 	}
 
 	properties := map[string]interface{}{
-		"charge_id":            obj["charge"],
+		"charge_id":            tr.GetId(obj, "charge"),
 		"amount":               obj["amount"],
 		"status":               obj["status"],
 		"currency":             obj["currency"],
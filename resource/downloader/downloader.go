@@ -5,9 +5,9 @@ import (
 	"github.com/apex/log"
 	"github.com/segment-sources/stripe/api"
 	"github.com/segment-sources/stripe/integration"
+	"github.com/segment-sources/stripe/resource/tr"
 	"github.com/segmentio/ur-log"
 	"net/url"
-	"reflect"
 )
 
 // Client can download multiple subsequent pages of objects from Stripe API
@@ -18,6 +18,11 @@ type Client struct {
 
 func (d *Client) Do(ctx context.Context, task *Task) error {
 	first := task.Request
+	if len(task.Expand) > 0 {
+		expanded := *first
+		expanded.Expand = append(append([]string{}, first.Expand...), task.Expand...)
+		first = &expanded
+	}
 
 	if task.Collection != "" {
 		ctx, _ = urlog.GetContextualLogger(ctx, nil, log.Fields{
@@ -25,60 +30,186 @@ func (d *Client) Do(ctx context.Context, task *Task) error {
 		})
 	}
 
-	for next := first; next != nil; {
-		req := next
+	// listings of collections record their progress, so that a run stopped by the request quota can be continued
+	cp := integration.GetCheckpoint(ctx)
+	key := ""
+	var progress integration.ListProgress
+	if cp != nil && task.Collection != "" {
+		key = checkpointKey(task.Collection, first)
+		progress, _ = cp.Progress(key)
+	}
+	if progress.Done {
+		log.WithField("collection", task.Collection).Info("skipping listing that finished in a previous run")
+		return nil
+	}
+
+	if task.Slicing != nil {
+		return d.doSliced(ctx, task, first, newWindowProgress(cp, key), progress.Windows)
+	}
+
+	next := first
+	if progress.StartingAfter != "" || progress.Page != "" {
+		log.WithField("collection", task.Collection).Info("continuing listing stopped in a previous run")
+		next = nextPage(first, progress.StartingAfter, task.Collection)
+		if progress.Page != "" {
+			next.Qs.Set("page", progress.Page)
+		}
+	}
 
-		res, err := RetryGetList(ctx, d.ApiClient, req)
+	for next != nil {
+		p, err := d.fetchPage(ctx, task, next)
 		if err != nil {
-			if task.Collection != "" && task.Errors != nil {
-				task.Errors <- integration.CollectionError{
-					Collection: task.Collection,
-					Message:    "HTTP request failed",
-				}
-			}
-			return urlog.WrapError(ctx, err, "failed to fetch object list")
+			reportError(task, err)
+			return err
 		}
 
-		lastSeenId := ""
-		for _, obj := range res.Objects {
-			for _, p := range task.PostProcessors {
-				procCtx, _ := urlog.GetContextualLogger(ctx, nil, log.Fields{
-					"processor": reflect.TypeOf(p).String(),
-				})
-				if err := p(procCtx, obj, task); err != nil {
-					if task.Collection != "" && task.Errors != nil {
-						task.Errors <- integration.CollectionError{
-							Collection: task.Collection,
-							Message:    "HTTP request failed",
-						}
-					}
-					return urlog.WrapError(ctx, err, "processor failed")
-				}
-			}
+		if p.hasMore && p.nextPage != "" {
+			next = nextPage(next, "", task.Collection)
+			next.Qs.Set("page", p.nextPage)
+		} else if p.hasMore && p.lastSeenId != "" {
+			next = nextPage(next, p.lastSeenId, task.Collection)
+		} else {
+			next = nil
+		}
 
-			task.Output <- obj
-			if id, ok := obj["id"].(string); ok {
-				lastSeenId = id
-			}
+		if key != "" && next != nil {
+			cp.SetProgress(key, integration.ListProgress{
+				StartingAfter: next.Qs.Get("starting_after"),
+				Page:          next.Qs.Get("page"),
+				EventsSince:   eventsSince(p),
+			})
 		}
+	}
 
-		if res.HasMore && lastSeenId != "" {
-			next = &api.Request{
-				Url:           req.Url,
-				Qs:            url.Values{},
-				Headers:       req.Headers,
-				LogCollection: task.Collection,
-			}
-			for key, value := range req.Qs {
-				next.Qs[key] = value
+	if key != "" {
+		cp.Finish(key)
+	}
+	return nil
+}
+
+// checkpointKey identifies a listing of a collection across runs
+func checkpointKey(collection string, first *api.Request) string {
+	return collection + " " + first.Url + "?" + first.Qs.Encode()
+}
+
+// eventsSince returns the creation time of the last event of a page, or zero if it doesn't list events
+func eventsSince(p *page) int64 {
+	if tr.GetString(p.last, "object") != "event" {
+		return 0
+	}
+	return tr.GetNumber(p.last, "created")
+}
+
+// page describes a downloaded page, its objects aren't retained once they're sent to the output
+type page struct {
+	hasMore  bool
+	nextPage string
+	// lastSeenId is the id of the last object which is the cursor of the next page
+	lastSeenId string
+	count      int
+	first      api.Object
+	last       api.Object
+}
+
+// fetchPage downloads a single page of objects and sends them to the task's output after post-processing.
+// Objects are decoded one by one while the response is read if the API client supports it and the task
+// doesn't have post-processors.
+// Once a post-processor fails, the rest of the page isn't sent to the output.
+func (d *Client) fetchPage(ctx context.Context, task *Task, req *api.Request) (*page, error) {
+	p := &page{}
+	var pool *processingPool
+	if task.ProcessorWorkers > 1 && len(task.PostProcessors) > 0 {
+		pool = newProcessingPool(ctx, task)
+	}
+	emit := func(obj api.Object) error {
+		if pool != nil {
+			if err := pool.submit(obj); err != nil {
+				return err
 			}
-			next.Qs.Set("starting_after", lastSeenId)
 		} else {
-			next = nil
+			if err := runPostProcessors(ctx, obj, task); err != nil {
+				return err
+			}
+			task.Output <- obj
+		}
+
+		if id, ok := obj["id"].(string); ok {
+			p.lastSeenId = id
 		}
+		if p.count == 0 {
+			p.first = obj
+		}
+		p.last = obj
+		p.count++
+		return nil
 	}
 
-	return nil
+	var res *api.ObjectList
+	var err error
+	failed := false
+	// post-processors may make requests of their own, so the page is decoded before they run rather than
+	// keeping its response open, the client's timeout covers reading the body
+	if streamer, ok := d.ApiClient.(api.ListStreamer); ok && len(task.PostProcessors) == 0 {
+		res, err = RetryStreamList(ctx, streamer, req, func(obj api.Object) error {
+			err := emit(obj)
+			failed = err != nil
+			return err
+		})
+	} else {
+		res, err = RetryGetList(ctx, d.ApiClient, req)
+		if err == nil {
+			for _, obj := range res.Objects {
+				if err = emit(obj); err != nil {
+					failed = true
+					break
+				}
+			}
+		}
+	}
+	if pool != nil {
+		if poolErr := pool.wait(); poolErr != nil {
+			return nil, poolErr
+		}
+	}
+	if failed {
+		return nil, err
+	}
+	if err != nil {
+		return nil, urlog.WrapError(ctx, err, "failed to fetch object list")
+	}
+
+	p.hasMore = res.HasMore
+	p.nextPage = res.NextPage
+	return p, nil
+}
+
+// nextPage copies a request and sets its cursor
+func nextPage(req *api.Request, startingAfter string, collection string) *api.Request {
+	next := &api.Request{
+		Url:           req.Url,
+		Qs:            url.Values{},
+		Params:        req.Params,
+		Expand:        req.Expand,
+		Headers:       req.Headers,
+		LogCollection: collection,
+	}
+	for key, value := range req.Qs {
+		next.Qs[key] = value
+	}
+	if startingAfter != "" {
+		next.Qs.Set("starting_after", startingAfter)
+	}
+	return next
+}
+
+// reportError reports a collection error unless the request quota was exhausted, which stops the run gracefully
+func reportError(task *Task, err error) {
+	if task.Collection != "" && task.Errors != nil && !api.IsErrorQuotaExceeded(err) {
+		task.Errors <- integration.CollectionError{
+			Collection: task.Collection,
+			Message:    ErrorMessage(err),
+		}
+	}
 }
 
 func New(apiClient api.Client) *Client {
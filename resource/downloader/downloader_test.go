@@ -0,0 +1,138 @@
+package downloader
+
+import (
+	"context"
+	"errors"
+	"github.com/segment-sources/stripe/api"
+	"github.com/segmentio/backo-go"
+	"github.com/stretchr/testify/assert"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// searchClient serves two pages of search results
+type searchClient struct {
+	api.Client
+	queries []string
+}
+
+func (c *searchClient) GetList(ctx context.Context, req *api.Request) (*api.ObjectList, error) {
+	c.queries = append(c.queries, req.Qs.Encode())
+	if req.Qs.Get("page") == "" {
+		return &api.ObjectList{
+			Objects:  []api.Object{{"id": "cus_2"}},
+			HasMore:  true,
+			NextPage: "page_2",
+		}, nil
+	}
+	return &api.ObjectList{
+		Objects: []api.Object{{"id": "cus_1"}},
+	}, nil
+}
+
+func TestSearchPagination(t *testing.T) {
+	client := &searchClient{}
+	output := make(chan api.Object, 2)
+
+	err := New(client).Do(context.Background(), &Task{
+		Request: &api.Request{
+			Url: "/v1/customers/search",
+			Qs:  url.Values{"query": {"created>1600000000"}},
+		},
+		Output: output,
+	})
+	close(output)
+
+	a := assert.New(t)
+	a.NoError(err)
+	a.Equal([]string{
+		"query=created%3E1600000000",
+		"page=page_2&query=created%3E1600000000",
+	}, client.queries)
+	ids := []string{}
+	for obj := range output {
+		ids = append(ids, obj["id"].(string))
+	}
+	a.Equal([]string{"cus_2", "cus_1"}, ids)
+}
+
+// flakyStreamer fails while reading the first response after sending a single object
+type flakyStreamer struct {
+	api.Client
+	attempts int
+}
+
+func (c *flakyStreamer) StreamList(ctx context.Context, req *api.Request, fn func(api.Object) error) (*api.ObjectList, error) {
+	c.attempts++
+	for _, id := range []string{"ch_3", "ch_2", "ch_1"} {
+		if err := fn(api.Object{"id": id}); err != nil {
+			return nil, err
+		}
+		if c.attempts == 1 {
+			return nil, errors.New("unexpected EOF")
+		}
+	}
+	return &api.ObjectList{}, nil
+}
+
+func TestStreamedPageRetry(t *testing.T) {
+	defer func(b *backo.Backo) { retryBackoff = b }(retryBackoff)
+	retryBackoff = backo.NewBacko(time.Millisecond, 2, 0, time.Millisecond)
+	client := &flakyStreamer{}
+	output := make(chan api.Object, 3)
+
+	err := New(client).Do(context.Background(), &Task{
+		Request: &api.Request{Url: "/v1/charges"},
+		Output:  output,
+	})
+	close(output)
+
+	a := assert.New(t)
+	a.NoError(err)
+	a.Equal(2, client.attempts)
+	ids := []string{}
+	for obj := range output {
+		ids = append(ids, obj["id"].(string))
+	}
+	a.Equal([]string{"ch_3", "ch_2", "ch_1"}, ids)
+}
+
+// listingStreamer records whether pages were streamed or decoded as a whole
+type listingStreamer struct {
+	api.Client
+	streamed int
+	listed   int
+}
+
+func (c *listingStreamer) StreamList(ctx context.Context, req *api.Request, fn func(api.Object) error) (*api.ObjectList, error) {
+	c.streamed++
+	return &api.ObjectList{}, fn(api.Object{"id": "ch_1"})
+}
+
+func (c *listingStreamer) GetList(ctx context.Context, req *api.Request) (*api.ObjectList, error) {
+	c.listed++
+	return &api.ObjectList{Objects: []api.Object{{"id": "ch_1"}}}, nil
+}
+
+func TestPostProcessedPagesAreNotStreamed(t *testing.T) {
+	client := &listingStreamer{}
+	output := make(chan api.Object, 2)
+	d := New(client)
+	a := assert.New(t)
+
+	a.NoError(d.Do(context.Background(), &Task{Request: &api.Request{Url: "/v1/charges"}, Output: output}))
+	a.Equal(1, client.streamed)
+
+	// post-processors' requests don't run while the page's response is open
+	a.NoError(d.Do(context.Background(), &Task{
+		Request: &api.Request{Url: "/v1/charges"},
+		Output:  output,
+		PostProcessors: []PostProcessor{func(ctx context.Context, obj api.Object, task *Task) error {
+			return nil
+		}},
+	}))
+	a.Equal(1, client.streamed)
+	a.Equal(1, client.listed)
+	a.Len(output, 2)
+}
@@ -0,0 +1,107 @@
+package downloader
+
+import (
+	"context"
+	"errors"
+	"github.com/apex/log"
+	"github.com/segment-sources/stripe/api"
+	"github.com/segmentio/ur-log"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// errProcessingStopped is returned for objects listed after a post-processor failed,
+// the failure itself is returned by processingPool.wait
+var errProcessingStopped = errors.New("post-processing stopped")
+
+// processingPool runs post-processors of a page's objects concurrently. Objects that are listed after
+// an object whose post-processing failed aren't sent to the output, the same as if they were processed
+// serially, but their post-processors may have already run. In unordered mode, an object is sent once
+// the objects listed before it were post-processed, rather than once they were sent.
+type processingPool struct {
+	ctx     context.Context
+	task    *Task
+	workers chan struct{}
+	wg      sync.WaitGroup
+	failed  int32
+	// previous is closed when the previously submitted object was sent to the output or dropped,
+	// or in unordered mode when it was post-processed
+	previous chan struct{}
+	mu       sync.Mutex
+	err      error
+}
+
+func newProcessingPool(ctx context.Context, task *Task) *processingPool {
+	previous := make(chan struct{})
+	close(previous)
+
+	return &processingPool{
+		ctx:      ctx,
+		task:     task,
+		workers:  make(chan struct{}, task.ProcessorWorkers),
+		previous: previous,
+	}
+}
+
+// submit blocks until a worker is available and starts post-processing of the object
+func (p *processingPool) submit(obj api.Object) error {
+	if atomic.LoadInt32(&p.failed) > 0 {
+		return errProcessingStopped
+	}
+
+	p.workers <- struct{}{}
+	previous, done := p.previous, make(chan struct{})
+	p.previous = done
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+
+		err := runPostProcessors(p.ctx, obj, p.task)
+		<-p.workers
+		if err != nil {
+			atomic.StoreInt32(&p.failed, 1)
+		}
+
+		// results are recorded in the order of listing, so the first failure is reported and
+		// only objects listed after it are dropped
+		<-previous
+		p.mu.Lock()
+		if err != nil && p.err == nil {
+			p.err = err
+		}
+		stopped := p.err != nil
+		p.mu.Unlock()
+
+		if p.task.UnorderedOutput {
+			close(done)
+		} else {
+			defer close(done)
+		}
+		if !stopped {
+			p.task.Output <- obj
+		}
+	}()
+
+	return nil
+}
+
+// wait returns the error of the failed post-processor after every submitted object is handled
+func (p *processingPool) wait() error {
+	p.wg.Wait()
+	return p.err
+}
+
+func runPostProcessors(ctx context.Context, obj api.Object, task *Task) error {
+	for _, processor := range task.PostProcessors {
+		procCtx, _ := urlog.GetContextualLogger(ctx, nil, log.Fields{
+			"processor": reflect.TypeOf(processor).String(),
+		})
+		if err := processor(procCtx, obj, task); err != nil {
+			return urlog.WrapError(ctx, err, "processor failed")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package downloader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/segment-sources/stripe/api"
+	"github.com/segment-sources/stripe/integration"
+	"github.com/stretchr/testify/assert"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// pageClient serves a single page of objects
+type pageClient struct {
+	api.Client
+	objects []api.Object
+}
+
+func (c *pageClient) GetList(ctx context.Context, req *api.Request) (*api.ObjectList, error) {
+	return &api.ObjectList{Objects: c.objects}, nil
+}
+
+func newPageClient(count int) *pageClient {
+	c := &pageClient{}
+	for i := 0; i < count; i++ {
+		c.objects = append(c.objects, api.Object{"id": fmt.Sprintf("obj_%d", i)})
+	}
+	return c
+}
+
+// slowProcessor takes longer for objects listed earlier so that they finish out of order
+func slowProcessor(running *int32, maxRunning *int32) PostProcessor {
+	return func(ctx context.Context, obj api.Object, task *Task) error {
+		n := atomic.AddInt32(running, 1)
+		defer atomic.AddInt32(running, -1)
+		for {
+			max := atomic.LoadInt32(maxRunning)
+			if n <= max || atomic.CompareAndSwapInt32(maxRunning, max, n) {
+				break
+			}
+		}
+
+		var i int
+		fmt.Sscanf(obj["id"].(string), "obj_%d", &i)
+		time.Sleep(time.Duration(20-i) * time.Millisecond)
+		return nil
+	}
+}
+
+func TestConcurrentProcessingPreservesOrder(t *testing.T) {
+	var running, maxRunning int32
+	output := make(chan api.Object, 20)
+
+	err := New(newPageClient(20)).Do(context.Background(), &Task{
+		Request:          &api.Request{Url: "/v1/transfers"},
+		Output:           output,
+		PostProcessors:   []PostProcessor{slowProcessor(&running, &maxRunning)},
+		ProcessorWorkers: 4,
+	})
+	close(output)
+
+	a := assert.New(t)
+	a.NoError(err)
+	a.Equal(int32(4), maxRunning)
+	i := 0
+	for obj := range output {
+		a.Equal(fmt.Sprintf("obj_%d", i), obj["id"])
+		i++
+	}
+	a.Equal(20, i)
+}
+
+func TestConcurrentProcessingFailure(t *testing.T) {
+	output := make(chan api.Object, 20)
+	errs := make(chan integration.CollectionError, 1)
+
+	err := New(newPageClient(20)).Do(context.Background(), &Task{
+		Request: &api.Request{Url: "/v1/transfers"},
+		Output:  output,
+		Errors:  errs,
+		PostProcessors: []PostProcessor{func(ctx context.Context, obj api.Object, task *Task) error {
+			switch obj["id"] {
+			case "obj_5":
+				time.Sleep(10 * time.Millisecond)
+				return errors.New("obj_5 failed")
+			case "obj_7":
+				return errors.New("obj_7 failed")
+			}
+			return nil
+		}},
+		Collection:       "transfers",
+		ProcessorWorkers: 4,
+	})
+	close(output)
+
+	a := assert.New(t)
+	if !a.Error(err) {
+		return
+	}
+	a.Contains(err.Error(), "obj_5 failed")
+	a.Len(errs, 1)
+	ids := []string{}
+	for obj := range output {
+		ids = append(ids, obj["id"].(string))
+	}
+	a.Equal([]string{"obj_0", "obj_1", "obj_2", "obj_3", "obj_4"}, ids)
+}
+
+func TestUnorderedProcessingFailureKeepsEarlierObjects(t *testing.T) {
+	output := make(chan api.Object, 20)
+	errs := make(chan integration.CollectionError, 1)
+
+	err := New(newPageClient(20)).Do(context.Background(), &Task{
+		Request: &api.Request{Url: "/v1/transfers"},
+		Output:  output,
+		Errors:  errs,
+		PostProcessors: []PostProcessor{func(ctx context.Context, obj api.Object, task *Task) error {
+			switch obj["id"] {
+			case "obj_1", "obj_2":
+				// finish after obj_3 failed
+				time.Sleep(20 * time.Millisecond)
+			case "obj_3":
+				return errors.New("obj_3 failed")
+			}
+			return nil
+		}},
+		Collection:       "transfers",
+		ProcessorWorkers: 4,
+		UnorderedOutput:  true,
+	})
+	close(output)
+
+	a := assert.New(t)
+	if !a.Error(err) {
+		return
+	}
+	a.Contains(err.Error(), "obj_3 failed")
+	ids := map[string]bool{}
+	for obj := range output {
+		ids[obj["id"].(string)] = true
+	}
+	a.Equal(map[string]bool{"obj_0": true, "obj_1": true, "obj_2": true}, ids)
+}
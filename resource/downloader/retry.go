@@ -5,6 +5,7 @@ import (
 	"github.com/apex/log"
 	"github.com/segment-sources/stripe/api"
 	"github.com/segmentio/backo-go"
+	"io"
 	"time"
 )
 
@@ -24,6 +25,39 @@ func RetryGetList(ctx context.Context, client api.Client, req *api.Request) (*ap
 	return res.(*api.ObjectList), nil
 }
 
+// RetryStreamList passes every object of a list to fn once. If reading the response fails, the request
+// is retried and objects that were already passed to fn are skipped. Errors returned by fn aren't retried.
+func RetryStreamList(ctx context.Context, client api.ListStreamer, req *api.Request, fn func(api.Object) error) (*api.ObjectList, error) {
+	seen := map[string]bool{}
+	var fnErr error
+	res, err := RetryApiCall(func() (interface{}, error) {
+		res, err := client.StreamList(ctx, req, func(obj api.Object) error {
+			if id, ok := obj["id"].(string); ok {
+				if seen[id] {
+					return nil
+				}
+				seen[id] = true
+			}
+
+			fnErr = fn(obj)
+			return fnErr
+		})
+		if fnErr != nil {
+			return nil, nil
+		}
+		return res, err
+	})
+
+	if fnErr != nil {
+		return nil, fnErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return res.(*api.ObjectList), nil
+}
+
 func RetryGetObject(ctx context.Context, client api.Client, req *api.Request) (api.Object, error) {
 	res, err := RetryApiCall(func() (interface{}, error) {
 		return client.GetObject(ctx, req)
@@ -36,6 +70,53 @@ func RetryGetObject(ctx context.Context, client api.Client, req *api.Request) (a
 	return res.(api.Object), nil
 }
 
+// RetryPost sends the same Idempotency-Key with every attempt
+func RetryPost(ctx context.Context, client api.Client, req *api.Request) (api.Object, error) {
+	req = api.WithIdempotencyKey(req)
+	res, err := RetryApiCall(func() (interface{}, error) {
+		return client.Post(ctx, req)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return res.(api.Object), nil
+}
+
+func RetryDelete(ctx context.Context, client api.Client, req *api.Request) (api.Object, error) {
+	res, err := RetryApiCall(func() (interface{}, error) {
+		return client.Delete(ctx, req)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return res.(api.Object), nil
+}
+
+// RetryStreamFile retries the request until the file is passed to fn, errors returned by fn aren't retried
+// since a part of the file may have been processed already
+func RetryStreamFile(ctx context.Context, client api.Client, req *api.Request, fn func(io.Reader) error) error {
+	var fnErr error
+	_, err := RetryApiCall(func() (interface{}, error) {
+		err := client.StreamFile(ctx, req, func(body io.Reader) error {
+			fnErr = fn(body)
+			return fnErr
+		})
+		if fnErr != nil {
+			return nil, nil
+		}
+		return nil, err
+	})
+
+	if fnErr != nil {
+		return fnErr
+	}
+	return err
+}
+
 func RetryApiCall(f func() (interface{}, error)) (resp interface{}, err error) {
 	attemptsLeft := retryMaxAttempts
 	for attemptsLeft > 0 {
@@ -64,3 +145,12 @@ func RetryApiCall(f func() (interface{}, error)) (resp interface{}, err error) {
 
 	return
 }
+
+// ErrorMessage returns Stripe's description of a failed API call to be used in collection errors
+func ErrorMessage(err error) string {
+	if stripeErr := api.GetStripeError(err); stripeErr != nil {
+		return stripeErr.Reason()
+	}
+
+	return "HTTP request failed"
+}
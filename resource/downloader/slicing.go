@@ -0,0 +1,249 @@
+package downloader
+
+import (
+	"context"
+	"fmt"
+	"github.com/apex/log"
+	"github.com/segment-sources/stripe/api"
+	"github.com/segment-sources/stripe/integration"
+	"github.com/segment-sources/stripe/resource/tr"
+	"github.com/segmentio/ur-log"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const defaultObjectsPerWindow = 10000
+
+// stripeEpoch precedes the creation of any Stripe object
+var stripeEpoch = time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Slicing splits a full sync into windows of the objects' creation time that are downloaded concurrently.
+// Windows are discovered adaptively, if the first page of a window suggests that it holds more than
+// ObjectsPerWindow objects, its older part is split off into new windows.
+type Slicing struct {
+	// Workers is the number of windows downloaded at the same time, all of them share the client's throttler
+	Workers int
+	// ObjectsPerWindow is the estimated number of objects above which a window is split
+	ObjectsPerWindow int
+}
+
+// NewSlicing returns nil if there aren't enough workers to download windows concurrently
+func NewSlicing(workers int) *Slicing {
+	if workers < 2 {
+		return nil
+	}
+
+	return &Slicing{
+		Workers:          workers,
+		ObjectsPerWindow: defaultObjectsPerWindow,
+	}
+}
+
+// window covers objects created in [gte, lt), startingAfter is the checkpoint of its pagination
+type window struct {
+	gte           int64
+	lt            int64
+	startingAfter string
+}
+
+func (w *window) request(first *api.Request, collection string) *api.Request {
+	req := nextPage(first, w.startingAfter, collection)
+	req.Qs.Set("created[gte]", fmt.Sprintf("%d", w.gte))
+	req.Qs.Set("created[lt]", fmt.Sprintf("%d", w.lt))
+	return req
+}
+
+// windowProgress records the unfinished windows of a sliced listing in the run's checkpoint
+type windowProgress struct {
+	checkpoint *integration.Checkpoint
+	key        string
+	mu         sync.Mutex
+	windows    map[*window]integration.ListWindow
+}
+
+// newWindowProgress returns nil if the listing's progress isn't recorded
+func newWindowProgress(checkpoint *integration.Checkpoint, key string) *windowProgress {
+	if checkpoint == nil || key == "" {
+		return nil
+	}
+
+	return &windowProgress{
+		checkpoint: checkpoint,
+		key:        key,
+		windows:    map[*window]integration.ListWindow{},
+	}
+}
+
+// update must be called by the goroutine that downloads the window, or before it starts
+func (p *windowProgress) update(w *window, finished bool) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if finished {
+		delete(p.windows, w)
+	} else {
+		p.windows[w] = integration.ListWindow{Gte: w.gte, Lt: w.lt, StartingAfter: w.startingAfter}
+	}
+
+	if len(p.windows) < 1 {
+		p.checkpoint.Finish(p.key)
+		return
+	}
+	windows := []integration.ListWindow{}
+	for _, lw := range p.windows {
+		windows = append(windows, lw)
+	}
+	p.checkpoint.SetProgress(p.key, integration.ListProgress{Windows: windows})
+}
+
+// doSliced downloads all windows and stops starting new pages after the first failure.
+// Windows of a listing stopped in a previous run are continued instead of starting over.
+func (d *Client) doSliced(ctx context.Context, task *Task, first *api.Request, progress *windowProgress, saved []integration.ListWindow) error {
+	workers := make(chan struct{}, task.Slicing.Workers)
+	wg := sync.WaitGroup{}
+
+	var failed int32
+	var firstErr error
+	errOnce := sync.Once{}
+	stopped := func() bool {
+		return atomic.LoadInt32(&failed) > 0
+	}
+
+	var run func(w *window)
+	run = func(w *window) {
+		defer wg.Done()
+		workers <- struct{}{}
+		defer func() { <-workers }()
+
+		err := d.downloadWindow(ctx, task, first, w, stopped, func(split *window) {
+			progress.update(split, false)
+			wg.Add(1)
+			go run(split)
+		}, func(finished bool) {
+			progress.update(w, finished)
+		})
+		if err != nil {
+			atomic.StoreInt32(&failed, 1)
+			errOnce.Do(func() {
+				firstErr = err
+				reportError(task, err)
+			})
+		}
+	}
+
+	windows := []*window{}
+	for _, lw := range saved {
+		windows = append(windows, &window{gte: lw.Gte, lt: lw.Lt, startingAfter: lw.StartingAfter})
+	}
+	if len(windows) > 0 {
+		log.WithField("collection", task.Collection).Info("continuing listing stopped in a previous run")
+	} else {
+		windows = append(windows, initialWindow(first))
+	}
+	for _, w := range windows {
+		progress.update(w, false)
+	}
+	for _, w := range windows {
+		wg.Add(1)
+		go run(w)
+	}
+	wg.Wait()
+
+	return firstErr
+}
+
+// initialWindow covers all objects unless the request is already bounded by created filters
+func initialWindow(first *api.Request) *window {
+	w := &window{
+		gte: stripeEpoch.Unix(),
+		lt:  time.Now().Add(time.Hour).Unix(),
+	}
+	if gte, err := strconv.ParseInt(first.Qs.Get("created[gte]"), 10, 64); err == nil {
+		w.gte = gte
+	}
+	if gt, err := strconv.ParseInt(first.Qs.Get("created[gt]"), 10, 64); err == nil {
+		w.gte = gt + 1
+	}
+	if lt, err := strconv.ParseInt(first.Qs.Get("created[lt]"), 10, 64); err == nil {
+		w.lt = lt
+	}
+	return w
+}
+
+// downloadWindow calls paged after every downloaded page and once the window is finished
+func (d *Client) downloadWindow(ctx context.Context, task *Task, first *api.Request, w *window, stopped func() bool, split func(*window), paged func(finished bool)) error {
+	for isFirstPage := true; !stopped(); isFirstPage = false {
+		windowCtx, _ := urlog.GetContextualLogger(ctx, nil, log.Fields{
+			"window": log.Fields{
+				"gte": w.gte,
+				"lt":  w.lt,
+			},
+		})
+
+		p, err := d.fetchPage(windowCtx, task, w.request(first, task.Collection))
+		if err != nil {
+			return err
+		}
+		if !p.hasMore || p.lastSeenId == "" {
+			paged(true)
+			return nil
+		}
+		w.startingAfter = p.lastSeenId
+
+		if isFirstPage {
+			// objects are listed from the newest
+			newest := tr.GetNumber(p.first, "created")
+			oldest := tr.GetNumber(p.last, "created")
+			splitWindow(task.Slicing, w, p.count, newest, oldest, split)
+		}
+		paged(false)
+	}
+
+	return nil
+}
+
+// splitWindow estimates the number of objects in the part of the window that wasn't downloaded yet,
+// assuming that it has the same density as the first page. If it's too large, the part is split off
+// into new windows of equal width and the window only continues with objects created in the same second
+// as the oldest object of the first page.
+func splitWindow(s *Slicing, w *window, count int, newest int64, oldest int64, split func(*window)) {
+	if oldest <= w.gte || oldest > w.lt {
+		return
+	}
+
+	firstPageSpan := newest - oldest
+	if firstPageSpan < 1 {
+		firstPageSpan = 1
+	}
+	remaining := int64(count) * (oldest - w.gte) / firstPageSpan
+	if remaining <= int64(s.ObjectsPerWindow) {
+		return
+	}
+
+	n := remaining/int64(s.ObjectsPerWindow) + 1
+	if n > int64(s.Workers) {
+		n = int64(s.Workers)
+	}
+	if n > oldest-w.gte {
+		n = oldest - w.gte
+	}
+
+	width := (oldest - w.gte) / n
+	for i := int64(0); i < n; i++ {
+		sw := &window{
+			gte: w.gte + i*width,
+			lt:  w.gte + (i+1)*width,
+		}
+		if i == n-1 {
+			sw.lt = oldest
+		}
+		split(sw)
+	}
+
+	w.gte = oldest
+}
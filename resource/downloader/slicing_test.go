@@ -0,0 +1,225 @@
+package downloader
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/segment-sources/stripe/api"
+	"github.com/segment-sources/stripe/integration"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// listClient serves a list of objects sorted from the newest like Stripe does,
+// filtered by created[gte], created[lt] and starting_after if they're set
+type listClient struct {
+	api.Client
+	objects []api.Object
+	mu      sync.Mutex
+	windows map[string]bool
+}
+
+func (c *listClient) GetList(ctx context.Context, req *api.Request) (*api.ObjectList, error) {
+	c.mu.Lock()
+	c.windows[req.Qs.Get("created[gte]")+"-"+req.Qs.Get("created[lt]")] = true
+	c.mu.Unlock()
+
+	gte, _ := strconv.ParseInt(req.Qs.Get("created[gte]"), 10, 64)
+	lt, err := strconv.ParseInt(req.Qs.Get("created[lt]"), 10, 64)
+	if err != nil {
+		lt = math.MaxInt64
+	}
+	startingAfter := req.Qs.Get("starting_after")
+
+	res := &api.ObjectList{}
+	started := startingAfter == ""
+	for _, obj := range c.objects {
+		if !started {
+			started = obj["id"] == startingAfter
+			continue
+		}
+		created, _ := obj["created"].(json.Number).Int64()
+		if created < gte || created >= lt {
+			continue
+		}
+		if len(res.Objects) == 10 {
+			res.HasMore = true
+			break
+		}
+		res.Objects = append(res.Objects, obj)
+	}
+	return res, nil
+}
+
+func TestSlicedDownload(t *testing.T) {
+	client := &listClient{windows: map[string]bool{}}
+	// 3 objects per second, most of them created recently
+	for i := 3000; i > 0; i-- {
+		created := stripeEpoch.Unix() + 1000000 + int64(i/3)
+		if i < 2000 {
+			created -= 500000
+		}
+		client.objects = append(client.objects, api.Object{
+			"id":      fmt.Sprintf("obj_%d", i),
+			"created": json.Number(fmt.Sprintf("%d", created)),
+		})
+	}
+
+	output := make(chan api.Object)
+	seen := map[string]int{}
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for obj := range output {
+			seen[obj["id"].(string)]++
+		}
+	}()
+
+	err := New(client).Do(context.Background(), &Task{
+		Request: &api.Request{Url: "/v1/charges"},
+		Output:  output,
+		Slicing: &Slicing{
+			Workers:          4,
+			ObjectsPerWindow: 100,
+		},
+	})
+	close(output)
+	wg.Wait()
+
+	a := assert.New(t)
+	a.NoError(err)
+	a.Len(seen, len(client.objects))
+	for id, count := range seen {
+		a.Equal(1, count, id)
+	}
+	a.True(len(client.windows) > 1)
+}
+
+// quotaClient fails with QuotaExceededError once it served a number of pages
+type quotaClient struct {
+	*listClient
+	mu        sync.Mutex
+	remaining int
+}
+
+func (c *quotaClient) GetList(ctx context.Context, req *api.Request) (*api.ObjectList, error) {
+	c.mu.Lock()
+	if c.remaining < 1 {
+		c.mu.Unlock()
+		return nil, &api.QuotaExceededError{}
+	}
+	c.remaining--
+	c.mu.Unlock()
+	return c.listClient.GetList(ctx, req)
+}
+
+// downloadWithQuota runs a task in a context with the checkpoint and returns the ids of downloaded objects
+func downloadWithQuota(client *listClient, pages int, cp *integration.Checkpoint, slicing *Slicing) ([]string, error) {
+	output := make(chan api.Object)
+	ids := []string{}
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for obj := range output {
+			ids = append(ids, obj["id"].(string))
+		}
+	}()
+
+	err := New(&quotaClient{listClient: client, remaining: pages}).Do(integration.WithCheckpoint(context.Background(), cp), &Task{
+		Collection: "charges",
+		Request:    &api.Request{Url: "/v1/charges", Qs: url.Values{"limit": {"10"}}},
+		Output:     output,
+		Slicing:    slicing,
+	})
+	close(output)
+	wg.Wait()
+	return ids, err
+}
+
+func newCreatedListClient(count int) *listClient {
+	client := &listClient{windows: map[string]bool{}}
+	for i := count; i > 0; i-- {
+		client.objects = append(client.objects, api.Object{
+			"id":      fmt.Sprintf("obj_%d", i),
+			"created": json.Number(fmt.Sprintf("%d", stripeEpoch.Unix()+int64(i*100))),
+		})
+	}
+	return client
+}
+
+func TestCheckpointedDownload(t *testing.T) {
+	client := newCreatedListClient(25)
+	cp := &integration.Checkpoint{}
+	a := assert.New(t)
+
+	ids, err := downloadWithQuota(client, 1, cp, nil)
+	a.True(api.IsErrorQuotaExceeded(err))
+	a.Len(ids, 10)
+	progress, ok := cp.Progress("charges /v1/charges?limit=10")
+	a.True(ok)
+	a.Equal(integration.ListProgress{StartingAfter: "obj_16"}, progress)
+
+	// the next run continues after the last listed object and finishes the listing
+	more, err := downloadWithQuota(client, 10, cp, nil)
+	a.NoError(err)
+	a.Len(more, 15)
+	a.Equal("obj_15", more[0])
+	progress, _ = cp.Progress("charges /v1/charges?limit=10")
+	a.True(progress.Done)
+
+	// a listing that finished isn't downloaded again
+	more, err = downloadWithQuota(client, 0, cp, nil)
+	a.NoError(err)
+	a.Empty(more)
+}
+
+func TestCheckpointedSlicedDownload(t *testing.T) {
+	client := newCreatedListClient(500)
+	cp := &integration.Checkpoint{}
+	slicing := &Slicing{Workers: 4, ObjectsPerWindow: 50}
+	a := assert.New(t)
+
+	seen := map[string]int{}
+	for run := 0; run < 10; run++ {
+		ids, err := downloadWithQuota(client, 10, cp, slicing)
+		for _, id := range ids {
+			seen[id]++
+		}
+		if err == nil {
+			break
+		}
+		a.True(api.IsErrorQuotaExceeded(err))
+		progress, _ := cp.Progress("charges /v1/charges?limit=10")
+		a.NotEmpty(progress.Windows)
+	}
+
+	progress, _ := cp.Progress("charges /v1/charges?limit=10")
+	a.True(progress.Done)
+	a.Len(seen, len(client.objects))
+	for id, count := range seen {
+		a.Equal(1, count, id)
+	}
+}
+
+func TestInitialWindow(t *testing.T) {
+	a := assert.New(t)
+
+	w := initialWindow(&api.Request{Url: "/v1/charges", Qs: url.Values{}})
+	a.Equal(stripeEpoch.Unix(), w.gte)
+
+	w = initialWindow(&api.Request{Url: "/v1/charges", Qs: url.Values{
+		"created[gte]": {"1500000000"},
+		"created[lt]":  {"1600000000"},
+	}})
+	a.Equal(int64(1500000000), w.gte)
+	a.Equal(int64(1600000000), w.lt)
+
+	w = initialWindow(&api.Request{Url: "/v1/charges", Qs: url.Values{"created[gt]": {"1500000000"}}})
+	a.Equal(int64(1500000001), w.gte)
+}
@@ -19,6 +19,17 @@ type Task struct {
 	Errors chan integration.CollectionError
 	// PostProcessors is a list of functions that should be called on each retrieved object
 	PostProcessors []PostProcessor
+	// ProcessorWorkers is the number of objects that are post-processed at the same time, it should be used
+	// with post-processors that perform API calls. Objects are still sent to the output in the order they
+	// were listed unless UnorderedOutput is set.
+	ProcessorWorkers int
+	// UnorderedOutput lets objects be sent to the output once they and the objects listed before them are post-processed
+	UnorderedOutput bool
 	// Collection is a name that will be used when reporting collection errors
 	Collection string
+	// Expand lists fields that should be expanded in every downloaded object, e.g. "data.balance_transaction"
+	Expand []string
+	// Slicing enables concurrent download of time windows, it may only be used for list endpoints
+	// that support created filters and when the order of objects doesn't matter
+	Slicing *Slicing
 }
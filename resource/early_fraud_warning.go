@@ -0,0 +1,141 @@
+package resource
+
+import (
+	"context"
+	"github.com/segment-sources/stripe/api"
+	"github.com/segment-sources/stripe/integration"
+	"github.com/segment-sources/stripe/resource/dedupe"
+	"github.com/segment-sources/stripe/resource/downloader"
+	"github.com/segment-sources/stripe/resource/tr"
+	"github.com/segmentio/go-source"
+)
+
+var earlyFraudWarningEvents = []string{
+	"radar.early_fraud_warning.created",
+	"radar.early_fraud_warning.updated",
+}
+
+type EarlyFraudWarning struct {
+	name      string
+	apiClient api.Client
+	objs      chan api.Object
+	msgs      chan source.SetMessage
+	errs      chan integration.CollectionError
+	dedupe    dedupe.Interface
+}
+
+func (r *EarlyFraudWarning) DesiredObjects() []string {
+	return []string{"radar.early_fraud_warning"}
+}
+
+func (r *EarlyFraudWarning) DesiredEvents() []string {
+	return earlyFraudWarningEvents
+}
+
+func (r *EarlyFraudWarning) ListEndpoint() string {
+	return "/v1/radar/early_fraud_warnings"
+}
+
+func (r *EarlyFraudWarning) ListTask() *downloader.Task {
+	return &downloader.Task{
+		Collection: r.name,
+		Request: &api.Request{
+			Url:           "/v1/radar/early_fraud_warnings?limit=100",
+			LogCollection: r.name,
+		},
+		Output: r.objs,
+		Errors: r.errs,
+	}
+}
+
+func (r *EarlyFraudWarning) StartProducer(ctx context.Context, runContext integration.RunContext) error {
+	defer close(r.objs)
+	defer close(r.errs)
+	if runContext.PreviousRunTimestamp.IsZero() {
+		if !runContext.StaleRunTimestamp.IsZero() {
+			return partialResync(ctx, r.apiClient, r, r.ListTask(), runContext.StaleRunTimestamp)
+		}
+		return downloader.New(r.apiClient).Do(ctx, r.ListTask())
+	}
+
+	// downloading events in incremental mode is handled by the bundle that this resource is a part of
+	return backfill(ctx, r.apiClient, r.ListTask(), runContext)
+}
+
+func (r *EarlyFraudWarning) StartConsumer(ctx context.Context, ch <-chan api.Object) {
+	defer close(r.msgs)
+	for obj := range ch {
+		switch tr.GetString(obj, "object") {
+		case "event":
+			if payload := tr.ExtractEventPayload(obj, "radar.early_fraud_warning"); payload != nil {
+				r.consumeEarlyFraudWarning(payload, true)
+			}
+		case "radar.early_fraud_warning":
+			r.consumeEarlyFraudWarning(obj, false)
+		}
+	}
+}
+
+func (r *EarlyFraudWarning) consumeEarlyFraudWarning(obj api.Object, fromEvent bool) {
+	if msg := r.transform(obj); msg != nil && !(fromEvent && r.dedupe.SeenBefore(msg.ID)) {
+		r.msgs <- *msg
+	}
+}
+
+func (r *EarlyFraudWarning) transform(obj api.Object) *source.SetMessage {
+	var id string
+	if id = tr.GetString(obj, "id"); id == "" {
+		return nil
+	}
+
+	properties := map[string]interface{}{
+		"actionable": obj["actionable"],
+		"charge_id":  tr.GetId(obj, "charge"),
+		"fraud_type": obj["fraud_type"],
+	}
+
+	if created := tr.GetTimestamp(obj, "created"); created != "" {
+		properties["created"] = created
+	}
+
+	return &source.SetMessage{
+		ID:         id,
+		Collection: r.name,
+		Properties: properties,
+	}
+}
+
+func (r *EarlyFraudWarning) Collection() string {
+	return r.name
+}
+
+func (r *EarlyFraudWarning) Objects() <-chan api.Object {
+	return r.objs
+}
+
+func (r *EarlyFraudWarning) Messages() <-chan source.SetMessage {
+	return r.msgs
+}
+
+func (r *EarlyFraudWarning) CollectionErrors() <-chan integration.CollectionError {
+	return r.errs
+}
+
+func (r *EarlyFraudWarning) Consumers() []integration.Consumer {
+	return []integration.Consumer{r}
+}
+
+func (r *EarlyFraudWarning) Close() {
+	r.dedupe.Close()
+}
+
+func NewEarlyFraudWarning(apiClient api.Client) *EarlyFraudWarning {
+	return &EarlyFraudWarning{
+		name:      "early_fraud_warnings",
+		apiClient: apiClient,
+		objs:      make(chan api.Object, 1000),
+		msgs:      make(chan source.SetMessage),
+		errs:      make(chan integration.CollectionError),
+		dedupe:    dedupe.New(),
+	}
+}
@@ -25,6 +25,8 @@ type Invoice struct {
 	msgs      chan source.SetMessage
 	errs      chan integration.CollectionError
 	dedupe    dedupe.Interface
+	slicing   *downloader.Slicing
+	search    bool
 }
 
 func (r *Invoice) DesiredObjects() []string {
@@ -35,26 +37,42 @@ func (r *Invoice) DesiredEvents() []string {
 	return invoiceEvents
 }
 
+func (r *Invoice) ListEndpoint() string {
+	return "/v1/invoices"
+}
+
+func (r *Invoice) ListTask() *downloader.Task {
+	return &downloader.Task{
+		Collection: r.name,
+		Request: &api.Request{
+			Url:           "/v1/invoices?limit=100",
+			LogCollection: r.name,
+		},
+		PostProcessors: []downloader.PostProcessor{
+			processors.NewListExpander("lines", r.apiClient),
+		},
+		Output:  r.objs,
+		Errors:  r.errs,
+		Slicing: r.slicing,
+	}
+}
+
 func (r *Invoice) StartProducer(ctx context.Context, runContext integration.RunContext) error {
 	defer close(r.objs)
 	defer close(r.errs)
 	if runContext.PreviousRunTimestamp.IsZero() {
-		return downloader.New(r.apiClient).Do(ctx, &downloader.Task{
-			Collection: r.name,
-			Request: &api.Request{
-				Url:           "/v1/invoices?limit=100",
-				LogCollection: r.name,
-			},
-			PostProcessors: []downloader.PostProcessor{
-				processors.NewListExpander("lines", r.apiClient),
-			},
-			Output: r.objs,
-			Errors: r.errs,
-		})
+		task := r.ListTask()
+		if r.search && !runContext.StaleRunTimestamp.IsZero() {
+			return searchIncremental(ctx, r.apiClient, r, task, "/v1/invoices/search", runContext.StaleRunTimestamp)
+		}
+		if !runContext.StaleRunTimestamp.IsZero() {
+			return partialResync(ctx, r.apiClient, r, task, runContext.StaleRunTimestamp)
+		}
+		return downloader.New(r.apiClient).Do(ctx, task)
 	}
 
 	// downloading events in incremental mode is handled by the bundle that this resource is a part of
-	return nil
+	return backfill(ctx, r.apiClient, r.ListTask(), runContext)
 }
 
 func (r *Invoice) StartConsumer(ctx context.Context, ch <-chan api.Object) {
@@ -85,13 +103,13 @@ func (r *Invoice) transform(obj api.Object) *source.SetMessage {
 
 	properties := map[string]interface{}{
 		"amount_due":           obj["amount_due"],
-		"application_fee":      obj["application_fee"],
+		"application_fee":      tr.GetId(obj, "application_fee"),
 		"attempt_count":        obj["attempt_count"],
 		"attempted":            obj["attempted"],
-		"charge_id":            obj["charge"],
+		"charge_id":            tr.GetId(obj, "charge"),
 		"closed":               obj["closed"],
 		"currency":             obj["currency"],
-		"customer_id":          obj["customer"],
+		"customer_id":          tr.GetId(obj, "customer"),
 		"description":          obj["description"],
 		"ending_balance":       obj["ending_balance"],
 		"forgiven":             obj["forgiven"],
@@ -99,7 +117,7 @@ func (r *Invoice) transform(obj api.Object) *source.SetMessage {
 		"receipt_number":       obj["receipt_number"],
 		"starting_balance":     obj["starting_balance"],
 		"statement_descriptor": obj["statement_descriptor"],
-		"subscription_id":      obj["subscription"],
+		"subscription_id":      tr.GetId(obj, "subscription"),
 		"subtotal":             obj["subtotal"],
 		"tax":                  obj["tax"],
 		"tax_percent":          obj["tax_percent"],
@@ -157,7 +175,9 @@ func (r *Invoice) Close() {
 	r.dedupe.Close()
 }
 
-func NewInvoice(apiClient api.Client) *Invoice {
+// NewInvoice downloads time windows of a full sync concurrently if fullSyncWorkers is more than one.
+// If search is set, objects created since a stale previous run are searched for instead of a full sync.
+func NewInvoice(apiClient api.Client, fullSyncWorkers int, search bool) *Invoice {
 	return &Invoice{
 		name:      "invoices",
 		apiClient: apiClient,
@@ -165,5 +185,7 @@ func NewInvoice(apiClient api.Client) *Invoice {
 		msgs:      make(chan source.SetMessage),
 		errs:      make(chan integration.CollectionError),
 		dedupe:    dedupe.New(),
+		slicing:   downloader.NewSlicing(fullSyncWorkers),
+		search:    search,
 	}
 }
@@ -35,25 +35,39 @@ func (r *InvoiceItem) DesiredEvents() []string {
 	return invoiceitemEvents
 }
 
+func (r *InvoiceItem) ListEndpoint() string {
+	return "/v1/invoiceitems"
+}
+
+func (r *InvoiceItem) ListTask() *downloader.Task {
+	return &downloader.Task{
+		Collection: r.name,
+		Request: &api.Request{
+			Url:           "/v1/invoiceitems?limit=100",
+			LogCollection: r.name,
+		},
+		Output: r.objs,
+		Errors: r.errs,
+	}
+}
+
 func (r *InvoiceItem) StartProducer(ctx context.Context, runContext integration.RunContext) error {
 	defer close(r.objs)
 	defer close(r.errs)
 	var task *downloader.Task
 	if runContext.PreviousRunTimestamp.IsZero() {
-		task = &downloader.Task{
-			Collection: r.name,
-			Request: &api.Request{
-				Url:           "/v1/invoiceitems?limit=100",
-				LogCollection: r.name,
-			},
-			Output: r.objs,
-			Errors: r.errs,
+		task = r.ListTask()
+		if !runContext.StaleRunTimestamp.IsZero() {
+			return partialResync(ctx, r.apiClient, r, task, runContext.StaleRunTimestamp)
 		}
 	} else {
 		task = tasks.MakeIncremental(r, r.name, runContext.PreviousRunTimestamp, r.objs, r.errs)
 	}
 
-	return downloader.New(r.apiClient).Do(ctx, task)
+	if err := downloader.New(r.apiClient).Do(ctx, task); err != nil {
+		return err
+	}
+	return backfill(ctx, r.apiClient, r.ListTask(), runContext)
 }
 
 func (r *InvoiceItem) GetEventProcessors() []downloader.PostProcessor {
@@ -91,13 +105,13 @@ func (r *InvoiceItem) transform(obj api.Object) *source.SetMessage {
 	properties := map[string]interface{}{
 		"amount":          obj["amount"],
 		"currency":        obj["currency"],
-		"customer_id":     obj["customer"],
+		"customer_id":     tr.GetId(obj, "customer"),
 		"description":     obj["description"],
 		"discountable":    obj["discountable"],
-		"invoice_id":      obj["invoice"],
+		"invoice_id":      tr.GetId(obj, "invoice"),
 		"proration":       obj["proration"],
 		"quantity":        obj["quantity"],
-		"subscription_id": obj["subscription"],
+		"subscription_id": tr.GetId(obj, "subscription"),
 	}
 
 	if v, ok := obj["is_deleted"].(bool); ok && v {
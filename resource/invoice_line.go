@@ -7,6 +7,8 @@ import (
 	"github.com/segment-sources/stripe/api"
 	"github.com/segment-sources/stripe/integration"
 	"github.com/segment-sources/stripe/resource/dedupe"
+	"github.com/segment-sources/stripe/resource/downloader"
+	"github.com/segment-sources/stripe/resource/processors"
 	"github.com/segment-sources/stripe/resource/tr"
 	"github.com/segmentio/go-source"
 	"strings"
@@ -29,7 +31,15 @@ func (r *InvoiceLine) DesiredObjects() []string {
 }
 
 func (r *InvoiceLine) DesiredEvents() []string {
-	return invoiceEvents
+	return append([]string{"invoiceitem.deleted"}, invoiceEvents...)
+}
+
+// GetEventProcessors marks deleted invoice items the same way as InvoiceItem does, since events of
+// this bundle are routed to invoice items as well
+func (r *InvoiceLine) GetEventProcessors() []downloader.PostProcessor {
+	return []downloader.PostProcessor{
+		processors.NewIsDeleted("invoiceitem.deleted"),
+	}
 }
 
 func (r *InvoiceLine) StartProducer(ctx context.Context, runContext integration.RunContext) error {
@@ -46,6 +56,9 @@ func (r *InvoiceLine) StartConsumer(ctx context.Context, ch <-chan api.Object) {
 		case "event":
 			if payload := tr.ExtractEventPayload(obj, "invoice"); payload != nil {
 				r.consumeInvoice(payload, true)
+				r.consumeRemovedLines(obj, payload)
+			} else if payload := tr.ExtractEventPayload(obj, "invoiceitem"); payload != nil {
+				r.consumeDeletedItem(payload)
 			}
 		case "invoice":
 			r.consumeInvoice(obj, false)
@@ -61,11 +74,47 @@ func (r *InvoiceLine) consumeInvoice(obj api.Object, fromEvent bool) {
 
 	for _, line := range tr.GetMapList(tr.GetMap(obj, "lines"), "data") {
 		if msg := r.transform(obj, line); msg != nil {
+			if fromEvent {
+				// lines of the most recent version aren't deleted by older events that removed them
+				r.dedupe.SeenBefore(msg.ID)
+			}
+			r.msgs <- *msg
+		}
+	}
+}
+
+// consumeRemovedLines deletes lines that were removed from a draft invoice by an update event,
+// e.g. lines of invoice items that were deleted
+func (r *InvoiceLine) consumeRemovedLines(event api.Object, obj api.Object) {
+	for _, line := range removedItems(event, obj, "lines") {
+		if msg := r.transform(obj, line); msg != nil && !r.dedupe.SeenBefore(msg.ID) {
+			msg.Properties["is_deleted"] = true
 			r.msgs <- *msg
 		}
 	}
 }
 
+// consumeDeletedItem deletes the line of an invoice item that was deleted from a draft invoice,
+// invoice.updated events aren't sent for it
+func (r *InvoiceLine) consumeDeletedItem(item api.Object) {
+	invoiceId, _ := tr.GetId(item, "invoice").(string)
+	if invoiceId == "" {
+		return
+	}
+
+	// lines of invoice items have the item's id and period
+	line := api.Object{}
+	for k, v := range item {
+		line[k] = v
+	}
+	line["type"] = "invoiceitem"
+
+	if msg := r.transform(api.Object{"id": invoiceId}, line); msg != nil && !r.dedupe.SeenBefore(msg.ID) {
+		msg.Properties["is_deleted"] = true
+		r.msgs <- *msg
+	}
+}
+
 func (r *InvoiceLine) transform(invoice, line api.Object) *source.SetMessage {
 	var invoiceId string
 	if invoiceId = tr.GetString(invoice, "id"); invoiceId == "" {
@@ -90,7 +139,7 @@ func (r *InvoiceLine) transform(invoice, line api.Object) *source.SetMessage {
 		"discountable":    line["discountable"],
 		"proration":       line["proration"],
 		"quantity":        line["quantity"],
-		"subscription_id": line["subscription"],
+		"subscription_id": tr.GetId(line, "subscription"),
 		"type":            line["type"],
 		"invoice_id":      invoiceId,
 	}
@@ -113,6 +162,10 @@ func (r *InvoiceLine) transform(invoice, line api.Object) *source.SetMessage {
 		}
 	}
 
+	if price := tr.GetMap(line, "price"); price != nil {
+		properties["price_id"] = price["id"]
+	}
+
 	if strings.HasPrefix(id, "sub_") {
 		properties["subscription_id"] = id
 	} else if strings.HasPrefix(id, "ii_") {
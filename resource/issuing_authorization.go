@@ -0,0 +1,156 @@
+package resource
+
+import (
+	"context"
+	"github.com/segment-sources/stripe/api"
+	"github.com/segment-sources/stripe/integration"
+	"github.com/segment-sources/stripe/resource/dedupe"
+	"github.com/segment-sources/stripe/resource/downloader"
+	"github.com/segment-sources/stripe/resource/tr"
+	"github.com/segmentio/go-source"
+)
+
+var issuingAuthorizationEvents = []string{
+	"issuing_authorization.created",
+	"issuing_authorization.updated",
+}
+
+type IssuingAuthorization struct {
+	name      string
+	apiClient api.Client
+	objs      chan api.Object
+	msgs      chan source.SetMessage
+	errs      chan integration.CollectionError
+	dedupe    dedupe.Interface
+}
+
+func (r *IssuingAuthorization) DesiredObjects() []string {
+	return []string{"issuing.authorization"}
+}
+
+func (r *IssuingAuthorization) DesiredEvents() []string {
+	return issuingAuthorizationEvents
+}
+
+func (r *IssuingAuthorization) ListEndpoint() string {
+	return "/v1/issuing/authorizations"
+}
+
+func (r *IssuingAuthorization) ListTask() *downloader.Task {
+	return &downloader.Task{
+		Collection: r.name,
+		Request: &api.Request{
+			Url:           "/v1/issuing/authorizations?limit=100",
+			LogCollection: r.name,
+		},
+		Output: r.objs,
+		Errors: r.errs,
+	}
+}
+
+func (r *IssuingAuthorization) StartProducer(ctx context.Context, runContext integration.RunContext) error {
+	defer close(r.objs)
+	defer close(r.errs)
+	if runContext.PreviousRunTimestamp.IsZero() {
+		if !runContext.StaleRunTimestamp.IsZero() {
+			return partialResync(ctx, r.apiClient, r, r.ListTask(), runContext.StaleRunTimestamp)
+		}
+		return downloader.New(r.apiClient).Do(ctx, r.ListTask())
+	}
+
+	// downloading events in incremental mode is handled by the bundle that this resource is a part of
+	return backfill(ctx, r.apiClient, r.ListTask(), runContext)
+}
+
+func (r *IssuingAuthorization) StartConsumer(ctx context.Context, ch <-chan api.Object) {
+	defer close(r.msgs)
+	for obj := range ch {
+		switch tr.GetString(obj, "object") {
+		case "event":
+			if payload := tr.ExtractEventPayload(obj, "issuing.authorization"); payload != nil {
+				r.consumeAuthorization(payload, true)
+			}
+		case "issuing.authorization":
+			r.consumeAuthorization(obj, false)
+		}
+	}
+}
+
+func (r *IssuingAuthorization) consumeAuthorization(obj api.Object, fromEvent bool) {
+	if msg := r.transform(obj); msg != nil && !(fromEvent && r.dedupe.SeenBefore(msg.ID)) {
+		r.msgs <- *msg
+	}
+}
+
+func (r *IssuingAuthorization) transform(obj api.Object) *source.SetMessage {
+	var id string
+	if id = tr.GetString(obj, "id"); id == "" {
+		return nil
+	}
+
+	properties := map[string]interface{}{
+		"amount":               obj["amount"],
+		"approved":             obj["approved"],
+		"authorization_method": obj["authorization_method"],
+		"cardholder_id":        obj["cardholder"],
+		"currency":             obj["currency"],
+		"merchant_amount":      obj["merchant_amount"],
+		"merchant_currency":    obj["merchant_currency"],
+		"status":               obj["status"],
+		"wallet":               obj["wallet"],
+	}
+
+	if card := tr.GetMap(obj, "card"); card != nil {
+		if cardId := tr.GetString(card, "id"); cardId != "" {
+			properties["card_id"] = cardId
+		}
+	}
+
+	tr.Flatten(tr.GetMap(obj, "merchant_data"), "merchant_data_", properties)
+	tr.Flatten(tr.GetMap(obj, "metadata"), "metadata_", properties)
+
+	if created := tr.GetTimestamp(obj, "created"); created != "" {
+		properties["created"] = created
+	}
+
+	return &source.SetMessage{
+		ID:         id,
+		Collection: r.name,
+		Properties: properties,
+	}
+}
+
+func (r *IssuingAuthorization) Collection() string {
+	return r.name
+}
+
+func (r *IssuingAuthorization) Objects() <-chan api.Object {
+	return r.objs
+}
+
+func (r *IssuingAuthorization) Messages() <-chan source.SetMessage {
+	return r.msgs
+}
+
+func (r *IssuingAuthorization) CollectionErrors() <-chan integration.CollectionError {
+	return r.errs
+}
+
+func (r *IssuingAuthorization) Consumers() []integration.Consumer {
+	return []integration.Consumer{r}
+}
+
+func (r *IssuingAuthorization) Close() {
+	r.dedupe.Close()
+}
+
+func NewIssuingAuthorization(apiClient api.Client) *IssuingAuthorization {
+	return &IssuingAuthorization{
+		name:      "issuing_authorizations",
+		apiClient: apiClient,
+		objs:      make(chan api.Object, 1000),
+		msgs:      make(chan source.SetMessage),
+		errs:      make(chan integration.CollectionError),
+		dedupe:    dedupe.New(),
+	}
+}
@@ -0,0 +1,131 @@
+package resource
+
+import (
+	"context"
+	"crypto/md5"
+	"fmt"
+	"github.com/segment-sources/stripe/api"
+	"github.com/segment-sources/stripe/integration"
+	"github.com/segment-sources/stripe/resource/dedupe"
+	"github.com/segment-sources/stripe/resource/tr"
+	"github.com/segmentio/go-source"
+)
+
+// IssuingAuthorizationRequest emits every entry of an authorization's request_history
+// as a separate row. Entries don't have ids of their own, so the row id is derived
+// from the authorization id and the entry's position in the history.
+type IssuingAuthorizationRequest struct {
+	name      string
+	apiClient api.Client
+	objs      chan api.Object
+	msgs      chan source.SetMessage
+	errs      chan integration.CollectionError
+	dedupe    dedupe.Interface
+}
+
+func (r *IssuingAuthorizationRequest) DesiredObjects() []string {
+	return []string{"issuing.authorization"}
+}
+
+func (r *IssuingAuthorizationRequest) DesiredEvents() []string {
+	return issuingAuthorizationEvents
+}
+
+func (r *IssuingAuthorizationRequest) StartProducer(ctx context.Context, runContext integration.RunContext) error {
+	// downloading events in incremental mode is handled by the bundle that this resource is a part of
+	close(r.objs)
+	close(r.errs)
+	return nil
+}
+
+func (r *IssuingAuthorizationRequest) StartConsumer(ctx context.Context, ch <-chan api.Object) {
+	defer close(r.msgs)
+	for obj := range ch {
+		switch tr.GetString(obj, "object") {
+		case "event":
+			if payload := tr.ExtractEventPayload(obj, "issuing.authorization"); payload != nil {
+				r.consumeAuthorization(payload, true)
+			}
+		case "issuing.authorization":
+			r.consumeAuthorization(obj, false)
+		}
+	}
+}
+
+func (r *IssuingAuthorizationRequest) consumeAuthorization(obj api.Object, fromEvent bool) {
+	var authorizationId string
+	if authorizationId = tr.GetString(obj, "id"); authorizationId == "" || fromEvent && r.dedupe.SeenBefore(authorizationId) {
+		return
+	}
+
+	for i, request := range tr.GetMapList(obj, "request_history") {
+		if msg := r.transform(obj, request, i); msg != nil {
+			r.msgs <- *msg
+		}
+	}
+}
+
+func (r *IssuingAuthorizationRequest) transform(authorization, request api.Object, index int) *source.SetMessage {
+	var authorizationId string
+	if authorizationId = tr.GetString(authorization, "id"); authorizationId == "" {
+		return nil
+	}
+
+	properties := map[string]interface{}{
+		"amount":            request["amount"],
+		"approved":          request["approved"],
+		"authorization_id":  authorizationId,
+		"currency":          request["currency"],
+		"merchant_amount":   request["merchant_amount"],
+		"merchant_currency": request["merchant_currency"],
+		"reason":            request["reason"],
+	}
+
+	if created := tr.GetTimestamp(request, "created"); created != "" {
+		properties["created"] = created
+	}
+
+	hash := md5.New()
+	fmt.Fprintf(hash, "%s, %d", authorizationId, index)
+
+	return &source.SetMessage{
+		ID:         fmt.Sprintf("%x", hash.Sum(nil)),
+		Collection: r.name,
+		Properties: properties,
+	}
+}
+
+func (r *IssuingAuthorizationRequest) Collection() string {
+	return r.name
+}
+
+func (r *IssuingAuthorizationRequest) Objects() <-chan api.Object {
+	return r.objs
+}
+
+func (r *IssuingAuthorizationRequest) Messages() <-chan source.SetMessage {
+	return r.msgs
+}
+
+func (r *IssuingAuthorizationRequest) CollectionErrors() <-chan integration.CollectionError {
+	return r.errs
+}
+
+func (r *IssuingAuthorizationRequest) Consumers() []integration.Consumer {
+	return []integration.Consumer{r}
+}
+
+func (r *IssuingAuthorizationRequest) Close() {
+	r.dedupe.Close()
+}
+
+func NewIssuingAuthorizationRequest(apiClient api.Client) *IssuingAuthorizationRequest {
+	return &IssuingAuthorizationRequest{
+		name:      "issuing_authorization_request_history",
+		apiClient: apiClient,
+		objs:      make(chan api.Object, 1000),
+		msgs:      make(chan source.SetMessage),
+		errs:      make(chan integration.CollectionError),
+		dedupe:    dedupe.New(),
+	}
+}
@@ -0,0 +1,120 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/segment-sources/stripe/api"
+	"github.com/segmentio/go-source"
+	"github.com/stretchr/testify/suite"
+	"sync"
+	"testing"
+)
+
+type IssuingAuthorizationRequestConsumerSuite struct {
+	suite.Suite
+
+	consumer           *IssuingAuthorizationRequest
+	inputAuthorization map[string]interface{}
+	expectedMessages   []source.SetMessage
+}
+
+func TestIssuingAuthorizationRequestConsumerSuite(t *testing.T) {
+	suite.Run(t, new(IssuingAuthorizationRequestConsumerSuite))
+}
+
+func (s *IssuingAuthorizationRequestConsumerSuite) SetupTest() {
+	s.inputAuthorization = map[string]interface{}{
+		"id":     "iauth_1JkQ6r2eZvKYlo2C",
+		"object": "issuing.authorization",
+		"request_history": []interface{}{
+			map[string]interface{}{
+				"amount":            json.Number("1500"),
+				"approved":          false,
+				"created":           json.Number("1633542637"),
+				"currency":          "usd",
+				"merchant_amount":   json.Number("1500"),
+				"merchant_currency": "usd",
+				"reason":            "webhook_declined",
+			},
+			map[string]interface{}{
+				"amount":            json.Number("1500"),
+				"approved":          true,
+				"created":           json.Number("1633542701"),
+				"currency":          "usd",
+				"merchant_amount":   json.Number("1500"),
+				"merchant_currency": "usd",
+				"reason":            "webhook_approved",
+			},
+		},
+	}
+	s.expectedMessages = []source.SetMessage{
+		{
+			ID:         "e4ac21c083dbbbdb24d09aded210c482",
+			Collection: "issuing_authorization_request_history",
+			Properties: map[string]interface{}{
+				"amount":            json.Number("1500"),
+				"approved":          false,
+				"authorization_id":  "iauth_1JkQ6r2eZvKYlo2C",
+				"created":           "2021-10-06T17:50:37.000Z",
+				"currency":          "usd",
+				"merchant_amount":   json.Number("1500"),
+				"merchant_currency": "usd",
+				"reason":            "webhook_declined",
+			},
+		},
+		{
+			ID:         "1f0054542792fe518b6741bc43f531e8",
+			Collection: "issuing_authorization_request_history",
+			Properties: map[string]interface{}{
+				"amount":            json.Number("1500"),
+				"approved":          true,
+				"authorization_id":  "iauth_1JkQ6r2eZvKYlo2C",
+				"created":           "2021-10-06T17:51:41.000Z",
+				"currency":          "usd",
+				"merchant_amount":   json.Number("1500"),
+				"merchant_currency": "usd",
+				"reason":            "webhook_approved",
+			},
+		},
+	}
+	s.consumer = NewIssuingAuthorizationRequest(nil)
+}
+
+func (s *IssuingAuthorizationRequestConsumerSuite) testConsumer(input api.Object, output []source.SetMessage) {
+	objs := make(chan api.Object, 1)
+	objs <- input
+	close(objs)
+
+	wg := sync.WaitGroup{}
+
+	msgs := []source.SetMessage{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for msg := range s.consumer.Messages() {
+			msgs = append(msgs, msg)
+		}
+	}()
+
+	s.consumer.StartConsumer(context.Background(), objs)
+	wg.Wait()
+
+	s.Equal(output, msgs)
+}
+
+func (s *IssuingAuthorizationRequestConsumerSuite) TestInputAuthorization() {
+	input := api.Object(s.inputAuthorization)
+	s.testConsumer(input, s.expectedMessages)
+}
+
+func (s *IssuingAuthorizationRequestConsumerSuite) TestInputEvent() {
+	input := api.Object{
+		"id":     "evt_1JkQ7s2eZvKYlo2C",
+		"object": "event",
+		"type":   "issuing_authorization.updated",
+		"data": map[string]interface{}{
+			"object": s.inputAuthorization,
+		},
+	}
+	s.testConsumer(input, s.expectedMessages)
+}
@@ -0,0 +1,158 @@
+package resource
+
+import (
+	"context"
+	"github.com/segment-sources/stripe/api"
+	"github.com/segment-sources/stripe/integration"
+	"github.com/segment-sources/stripe/resource/dedupe"
+	"github.com/segment-sources/stripe/resource/downloader"
+	"github.com/segment-sources/stripe/resource/tr"
+	"github.com/segmentio/go-source"
+)
+
+var issuingCardEvents = []string{
+	"issuing_card.created",
+	"issuing_card.updated",
+}
+
+type IssuingCard struct {
+	name      string
+	apiClient api.Client
+	objs      chan api.Object
+	msgs      chan source.SetMessage
+	errs      chan integration.CollectionError
+	dedupe    dedupe.Interface
+}
+
+func (r *IssuingCard) DesiredObjects() []string {
+	return []string{"issuing.card"}
+}
+
+func (r *IssuingCard) DesiredEvents() []string {
+	return issuingCardEvents
+}
+
+func (r *IssuingCard) ListEndpoint() string {
+	return "/v1/issuing/cards"
+}
+
+func (r *IssuingCard) ListTask() *downloader.Task {
+	return &downloader.Task{
+		Collection: r.name,
+		Request: &api.Request{
+			Url:           "/v1/issuing/cards?limit=100",
+			LogCollection: r.name,
+		},
+		Output: r.objs,
+		Errors: r.errs,
+	}
+}
+
+func (r *IssuingCard) StartProducer(ctx context.Context, runContext integration.RunContext) error {
+	defer close(r.objs)
+	defer close(r.errs)
+	if runContext.PreviousRunTimestamp.IsZero() {
+		if !runContext.StaleRunTimestamp.IsZero() {
+			return partialResync(ctx, r.apiClient, r, r.ListTask(), runContext.StaleRunTimestamp)
+		}
+		return downloader.New(r.apiClient).Do(ctx, r.ListTask())
+	}
+
+	// downloading events in incremental mode is handled by the bundle that this resource is a part of
+	return backfill(ctx, r.apiClient, r.ListTask(), runContext)
+}
+
+func (r *IssuingCard) StartConsumer(ctx context.Context, ch <-chan api.Object) {
+	defer close(r.msgs)
+	for obj := range ch {
+		switch tr.GetString(obj, "object") {
+		case "event":
+			if payload := tr.ExtractEventPayload(obj, "issuing.card"); payload != nil {
+				r.consumeCard(payload, true)
+			}
+		case "issuing.card":
+			r.consumeCard(obj, false)
+		}
+	}
+}
+
+func (r *IssuingCard) consumeCard(obj api.Object, fromEvent bool) {
+	if msg := r.transform(obj); msg != nil && !(fromEvent && r.dedupe.SeenBefore(msg.ID)) {
+		r.msgs <- *msg
+	}
+}
+
+func (r *IssuingCard) transform(obj api.Object) *source.SetMessage {
+	var id string
+	if id = tr.GetString(obj, "id"); id == "" {
+		return nil
+	}
+
+	properties := map[string]interface{}{
+		"brand":               obj["brand"],
+		"cancellation_reason": obj["cancellation_reason"],
+		"currency":            obj["currency"],
+		"exp_month":           obj["exp_month"],
+		"exp_year":            obj["exp_year"],
+		"last4":               obj["last4"],
+		"replaced_by":         obj["replaced_by"],
+		"replacement_for":     obj["replacement_for"],
+		"replacement_reason":  obj["replacement_reason"],
+		"status":              obj["status"],
+		"type":                obj["type"],
+	}
+
+	if cardholder := tr.GetMap(obj, "cardholder"); cardholder != nil {
+		if cardholderId := tr.GetString(cardholder, "id"); cardholderId != "" {
+			properties["cardholder_id"] = cardholderId
+		}
+	}
+
+	tr.Flatten(tr.GetMap(obj, "metadata"), "metadata_", properties)
+	tr.Flatten(tr.GetMap(obj, "shipping"), "shipping_", properties)
+
+	if created := tr.GetTimestamp(obj, "created"); created != "" {
+		properties["created"] = created
+	}
+
+	return &source.SetMessage{
+		ID:         id,
+		Collection: r.name,
+		Properties: properties,
+	}
+}
+
+func (r *IssuingCard) Collection() string {
+	return r.name
+}
+
+func (r *IssuingCard) Objects() <-chan api.Object {
+	return r.objs
+}
+
+func (r *IssuingCard) Messages() <-chan source.SetMessage {
+	return r.msgs
+}
+
+func (r *IssuingCard) CollectionErrors() <-chan integration.CollectionError {
+	return r.errs
+}
+
+func (r *IssuingCard) Consumers() []integration.Consumer {
+	return []integration.Consumer{r}
+}
+
+func (r *IssuingCard) Close() {
+	r.dedupe.Close()
+}
+
+func NewIssuingCard(apiClient api.Client) *IssuingCard {
+	return &IssuingCard{
+		name:      "issuing_cards",
+		apiClient: apiClient,
+		objs:      make(chan api.Object, 1000),
+		msgs:      make(chan source.SetMessage),
+		errs:      make(chan integration.CollectionError),
+		dedupe:    dedupe.New(),
+	}
+}
@@ -0,0 +1,146 @@
+package resource
+
+import (
+	"context"
+	"github.com/segment-sources/stripe/api"
+	"github.com/segment-sources/stripe/integration"
+	"github.com/segment-sources/stripe/resource/dedupe"
+	"github.com/segment-sources/stripe/resource/downloader"
+	"github.com/segment-sources/stripe/resource/tr"
+	"github.com/segmentio/go-source"
+)
+
+var issuingCardholderEvents = []string{
+	"issuing_cardholder.created",
+	"issuing_cardholder.updated",
+}
+
+type IssuingCardholder struct {
+	name      string
+	apiClient api.Client
+	objs      chan api.Object
+	msgs      chan source.SetMessage
+	errs      chan integration.CollectionError
+	dedupe    dedupe.Interface
+}
+
+func (r *IssuingCardholder) DesiredObjects() []string {
+	return []string{"issuing.cardholder"}
+}
+
+func (r *IssuingCardholder) DesiredEvents() []string {
+	return issuingCardholderEvents
+}
+
+func (r *IssuingCardholder) ListEndpoint() string {
+	return "/v1/issuing/cardholders"
+}
+
+func (r *IssuingCardholder) ListTask() *downloader.Task {
+	return &downloader.Task{
+		Collection: r.name,
+		Request: &api.Request{
+			Url:           "/v1/issuing/cardholders?limit=100",
+			LogCollection: r.name,
+		},
+		Output: r.objs,
+		Errors: r.errs,
+	}
+}
+
+func (r *IssuingCardholder) StartProducer(ctx context.Context, runContext integration.RunContext) error {
+	defer close(r.objs)
+	defer close(r.errs)
+	if runContext.PreviousRunTimestamp.IsZero() {
+		if !runContext.StaleRunTimestamp.IsZero() {
+			return partialResync(ctx, r.apiClient, r, r.ListTask(), runContext.StaleRunTimestamp)
+		}
+		return downloader.New(r.apiClient).Do(ctx, r.ListTask())
+	}
+
+	// downloading events in incremental mode is handled by the bundle that this resource is a part of
+	return backfill(ctx, r.apiClient, r.ListTask(), runContext)
+}
+
+func (r *IssuingCardholder) StartConsumer(ctx context.Context, ch <-chan api.Object) {
+	defer close(r.msgs)
+	for obj := range ch {
+		switch tr.GetString(obj, "object") {
+		case "event":
+			if payload := tr.ExtractEventPayload(obj, "issuing.cardholder"); payload != nil {
+				r.consumeCardholder(payload, true)
+			}
+		case "issuing.cardholder":
+			r.consumeCardholder(obj, false)
+		}
+	}
+}
+
+func (r *IssuingCardholder) consumeCardholder(obj api.Object, fromEvent bool) {
+	if msg := r.transform(obj); msg != nil && !(fromEvent && r.dedupe.SeenBefore(msg.ID)) {
+		r.msgs <- *msg
+	}
+}
+
+func (r *IssuingCardholder) transform(obj api.Object) *source.SetMessage {
+	var id string
+	if id = tr.GetString(obj, "id"); id == "" {
+		return nil
+	}
+
+	properties := map[string]interface{}{
+		"email":        obj["email"],
+		"name":         obj["name"],
+		"phone_number": obj["phone_number"],
+		"status":       obj["status"],
+		"type":         obj["type"],
+	}
+
+	tr.Flatten(tr.GetMap(obj, "billing"), "billing_", properties)
+	tr.Flatten(tr.GetMap(obj, "metadata"), "metadata_", properties)
+
+	if created := tr.GetTimestamp(obj, "created"); created != "" {
+		properties["created"] = created
+	}
+
+	return &source.SetMessage{
+		ID:         id,
+		Collection: r.name,
+		Properties: properties,
+	}
+}
+
+func (r *IssuingCardholder) Collection() string {
+	return r.name
+}
+
+func (r *IssuingCardholder) Objects() <-chan api.Object {
+	return r.objs
+}
+
+func (r *IssuingCardholder) Messages() <-chan source.SetMessage {
+	return r.msgs
+}
+
+func (r *IssuingCardholder) CollectionErrors() <-chan integration.CollectionError {
+	return r.errs
+}
+
+func (r *IssuingCardholder) Consumers() []integration.Consumer {
+	return []integration.Consumer{r}
+}
+
+func (r *IssuingCardholder) Close() {
+	r.dedupe.Close()
+}
+
+func NewIssuingCardholder(apiClient api.Client) *IssuingCardholder {
+	return &IssuingCardholder{
+		name:      "issuing_cardholders",
+		apiClient: apiClient,
+		objs:      make(chan api.Object, 1000),
+		msgs:      make(chan source.SetMessage),
+		errs:      make(chan integration.CollectionError),
+		dedupe:    dedupe.New(),
+	}
+}
@@ -0,0 +1,151 @@
+package resource
+
+import (
+	"context"
+	"github.com/segment-sources/stripe/api"
+	"github.com/segment-sources/stripe/integration"
+	"github.com/segment-sources/stripe/resource/dedupe"
+	"github.com/segment-sources/stripe/resource/downloader"
+	"github.com/segment-sources/stripe/resource/tr"
+	"github.com/segmentio/go-source"
+)
+
+var issuingDisputeEvents = []string{
+	"issuing_dispute.closed",
+	"issuing_dispute.created",
+	"issuing_dispute.funds_reinstated",
+	"issuing_dispute.submitted",
+	"issuing_dispute.updated",
+}
+
+type IssuingDispute struct {
+	name      string
+	apiClient api.Client
+	objs      chan api.Object
+	msgs      chan source.SetMessage
+	errs      chan integration.CollectionError
+	dedupe    dedupe.Interface
+}
+
+func (r *IssuingDispute) DesiredObjects() []string {
+	return []string{"issuing.dispute"}
+}
+
+func (r *IssuingDispute) DesiredEvents() []string {
+	return issuingDisputeEvents
+}
+
+func (r *IssuingDispute) ListEndpoint() string {
+	return "/v1/issuing/disputes"
+}
+
+func (r *IssuingDispute) ListTask() *downloader.Task {
+	return &downloader.Task{
+		Collection: r.name,
+		Request: &api.Request{
+			Url:           "/v1/issuing/disputes?limit=100",
+			LogCollection: r.name,
+		},
+		Output: r.objs,
+		Errors: r.errs,
+	}
+}
+
+func (r *IssuingDispute) StartProducer(ctx context.Context, runContext integration.RunContext) error {
+	defer close(r.objs)
+	defer close(r.errs)
+	if runContext.PreviousRunTimestamp.IsZero() {
+		if !runContext.StaleRunTimestamp.IsZero() {
+			return partialResync(ctx, r.apiClient, r, r.ListTask(), runContext.StaleRunTimestamp)
+		}
+		return downloader.New(r.apiClient).Do(ctx, r.ListTask())
+	}
+
+	// downloading events in incremental mode is handled by the bundle that this resource is a part of
+	return backfill(ctx, r.apiClient, r.ListTask(), runContext)
+}
+
+func (r *IssuingDispute) StartConsumer(ctx context.Context, ch <-chan api.Object) {
+	defer close(r.msgs)
+	for obj := range ch {
+		switch tr.GetString(obj, "object") {
+		case "event":
+			if payload := tr.ExtractEventPayload(obj, "issuing.dispute"); payload != nil {
+				r.consumeDispute(payload, true)
+			}
+		case "issuing.dispute":
+			r.consumeDispute(obj, false)
+		}
+	}
+}
+
+func (r *IssuingDispute) consumeDispute(obj api.Object, fromEvent bool) {
+	if msg := r.transform(obj); msg != nil && !(fromEvent && r.dedupe.SeenBefore(msg.ID)) {
+		r.msgs <- *msg
+	}
+}
+
+func (r *IssuingDispute) transform(obj api.Object) *source.SetMessage {
+	var id string
+	if id = tr.GetString(obj, "id"); id == "" {
+		return nil
+	}
+
+	properties := map[string]interface{}{
+		"amount":         obj["amount"],
+		"currency":       obj["currency"],
+		"status":         obj["status"],
+		"transaction_id": obj["transaction"],
+	}
+
+	if evidence := tr.GetMap(obj, "evidence"); evidence != nil {
+		properties["evidence_reason"] = evidence["reason"]
+	}
+
+	tr.Flatten(tr.GetMap(obj, "metadata"), "metadata_", properties)
+
+	if created := tr.GetTimestamp(obj, "created"); created != "" {
+		properties["created"] = created
+	}
+
+	return &source.SetMessage{
+		ID:         id,
+		Collection: r.name,
+		Properties: properties,
+	}
+}
+
+func (r *IssuingDispute) Collection() string {
+	return r.name
+}
+
+func (r *IssuingDispute) Objects() <-chan api.Object {
+	return r.objs
+}
+
+func (r *IssuingDispute) Messages() <-chan source.SetMessage {
+	return r.msgs
+}
+
+func (r *IssuingDispute) CollectionErrors() <-chan integration.CollectionError {
+	return r.errs
+}
+
+func (r *IssuingDispute) Consumers() []integration.Consumer {
+	return []integration.Consumer{r}
+}
+
+func (r *IssuingDispute) Close() {
+	r.dedupe.Close()
+}
+
+func NewIssuingDispute(apiClient api.Client) *IssuingDispute {
+	return &IssuingDispute{
+		name:      "issuing_disputes",
+		apiClient: apiClient,
+		objs:      make(chan api.Object, 1000),
+		msgs:      make(chan source.SetMessage),
+		errs:      make(chan integration.CollectionError),
+		dedupe:    dedupe.New(),
+	}
+}
@@ -0,0 +1,152 @@
+package resource
+
+import (
+	"context"
+	"github.com/segment-sources/stripe/api"
+	"github.com/segment-sources/stripe/integration"
+	"github.com/segment-sources/stripe/resource/dedupe"
+	"github.com/segment-sources/stripe/resource/downloader"
+	"github.com/segment-sources/stripe/resource/tr"
+	"github.com/segmentio/go-source"
+)
+
+var issuingTransactionEvents = []string{
+	"issuing_transaction.created",
+	"issuing_transaction.updated",
+}
+
+type IssuingTransaction struct {
+	name      string
+	apiClient api.Client
+	objs      chan api.Object
+	msgs      chan source.SetMessage
+	errs      chan integration.CollectionError
+	dedupe    dedupe.Interface
+}
+
+func (r *IssuingTransaction) DesiredObjects() []string {
+	return []string{"issuing.transaction"}
+}
+
+func (r *IssuingTransaction) DesiredEvents() []string {
+	return issuingTransactionEvents
+}
+
+func (r *IssuingTransaction) ListEndpoint() string {
+	return "/v1/issuing/transactions"
+}
+
+func (r *IssuingTransaction) ListTask() *downloader.Task {
+	return &downloader.Task{
+		Collection: r.name,
+		Request: &api.Request{
+			Url:           "/v1/issuing/transactions?limit=100",
+			LogCollection: r.name,
+		},
+		Output: r.objs,
+		Errors: r.errs,
+	}
+}
+
+func (r *IssuingTransaction) StartProducer(ctx context.Context, runContext integration.RunContext) error {
+	defer close(r.objs)
+	defer close(r.errs)
+	if runContext.PreviousRunTimestamp.IsZero() {
+		if !runContext.StaleRunTimestamp.IsZero() {
+			return partialResync(ctx, r.apiClient, r, r.ListTask(), runContext.StaleRunTimestamp)
+		}
+		return downloader.New(r.apiClient).Do(ctx, r.ListTask())
+	}
+
+	// downloading events in incremental mode is handled by the bundle that this resource is a part of
+	return backfill(ctx, r.apiClient, r.ListTask(), runContext)
+}
+
+func (r *IssuingTransaction) StartConsumer(ctx context.Context, ch <-chan api.Object) {
+	defer close(r.msgs)
+	for obj := range ch {
+		switch tr.GetString(obj, "object") {
+		case "event":
+			if payload := tr.ExtractEventPayload(obj, "issuing.transaction"); payload != nil {
+				r.consumeTransaction(payload, true)
+			}
+		case "issuing.transaction":
+			r.consumeTransaction(obj, false)
+		}
+	}
+}
+
+func (r *IssuingTransaction) consumeTransaction(obj api.Object, fromEvent bool) {
+	if msg := r.transform(obj); msg != nil && !(fromEvent && r.dedupe.SeenBefore(msg.ID)) {
+		r.msgs <- *msg
+	}
+}
+
+func (r *IssuingTransaction) transform(obj api.Object) *source.SetMessage {
+	var id string
+	if id = tr.GetString(obj, "id"); id == "" {
+		return nil
+	}
+
+	properties := map[string]interface{}{
+		"amount":                 obj["amount"],
+		"authorization_id":       obj["authorization"],
+		"balance_transaction_id": tr.GetId(obj, "balance_transaction"),
+		"card_id":                obj["card"],
+		"cardholder_id":          obj["cardholder"],
+		"currency":               obj["currency"],
+		"dispute_id":             tr.GetId(obj, "dispute"),
+		"merchant_amount":        obj["merchant_amount"],
+		"merchant_currency":      obj["merchant_currency"],
+		"type":                   obj["type"],
+		"wallet":                 obj["wallet"],
+	}
+
+	tr.Flatten(tr.GetMap(obj, "merchant_data"), "merchant_data_", properties)
+	tr.Flatten(tr.GetMap(obj, "metadata"), "metadata_", properties)
+
+	if created := tr.GetTimestamp(obj, "created"); created != "" {
+		properties["created"] = created
+	}
+
+	return &source.SetMessage{
+		ID:         id,
+		Collection: r.name,
+		Properties: properties,
+	}
+}
+
+func (r *IssuingTransaction) Collection() string {
+	return r.name
+}
+
+func (r *IssuingTransaction) Objects() <-chan api.Object {
+	return r.objs
+}
+
+func (r *IssuingTransaction) Messages() <-chan source.SetMessage {
+	return r.msgs
+}
+
+func (r *IssuingTransaction) CollectionErrors() <-chan integration.CollectionError {
+	return r.errs
+}
+
+func (r *IssuingTransaction) Consumers() []integration.Consumer {
+	return []integration.Consumer{r}
+}
+
+func (r *IssuingTransaction) Close() {
+	r.dedupe.Close()
+}
+
+func NewIssuingTransaction(apiClient api.Client) *IssuingTransaction {
+	return &IssuingTransaction{
+		name:      "issuing_transactions",
+		apiClient: apiClient,
+		objs:      make(chan api.Object, 1000),
+		msgs:      make(chan source.SetMessage),
+		errs:      make(chan integration.CollectionError),
+		dedupe:    dedupe.New(),
+	}
+}
@@ -34,6 +34,10 @@ func (r *Order) DesiredEvents() []string {
 	return orderEvents
 }
 
+func (r *Order) ListEndpoint() string {
+	return "/v1/orders"
+}
+
 func (r *Order) StartProducer(ctx context.Context, runContext integration.RunContext) error {
 	defer close(r.objs)
 	defer close(r.errs)
@@ -83,10 +87,10 @@ func (r *Order) transform(obj api.Object) *source.SetMessage {
 		"amount":                   obj["amount"],
 		"amount_returned":          obj["amount_returned"],
 		"application":              obj["application"],
-		"application_fee":          obj["application_fee"],
-		"charge_id":                obj["charge"],
+		"application_fee":          tr.GetId(obj, "application_fee"),
+		"charge_id":                tr.GetId(obj, "charge"),
 		"currency":                 obj["currency"],
-		"customer_id":              obj["customer"],
+		"customer_id":              tr.GetId(obj, "customer"),
 		"email":                    obj["email"],
 		"livemode":                 obj["livemode"],
 		"selected_shipping_method": obj["selected_shipping_method"],
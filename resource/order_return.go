@@ -32,6 +32,10 @@ func (r *OrderReturn) DesiredEvents() []string {
 	return orderReturnEvents
 }
 
+func (r *OrderReturn) ListEndpoint() string {
+	return "/v1/order_returns"
+}
+
 func (r *OrderReturn) StartProducer(ctx context.Context, runContext integration.RunContext) error {
 	defer close(r.objs)
 	defer close(r.errs)
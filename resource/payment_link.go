@@ -0,0 +1,137 @@
+package resource
+
+import (
+	"context"
+	"github.com/segment-sources/stripe/api"
+	"github.com/segment-sources/stripe/integration"
+	"github.com/segment-sources/stripe/resource/dedupe"
+	"github.com/segment-sources/stripe/resource/downloader"
+	"github.com/segment-sources/stripe/resource/tasks"
+	"github.com/segment-sources/stripe/resource/tr"
+	"github.com/segmentio/go-source"
+)
+
+var paymentLinkEvents = []string{
+	"payment_link.created",
+	"payment_link.updated",
+}
+
+type PaymentLink struct {
+	name      string
+	apiClient api.Client
+	objs      chan api.Object
+	msgs      chan source.SetMessage
+	errs      chan integration.CollectionError
+	dedupe    dedupe.Interface
+}
+
+func (r *PaymentLink) DesiredObjects() []string {
+	return []string{"payment_link"}
+}
+
+func (r *PaymentLink) DesiredEvents() []string {
+	return paymentLinkEvents
+}
+
+func (r *PaymentLink) ListEndpoint() string {
+	return "/v1/payment_links"
+}
+
+func (r *PaymentLink) StartProducer(ctx context.Context, runContext integration.RunContext) error {
+	defer close(r.objs)
+	defer close(r.errs)
+	var task *downloader.Task
+	if runContext.PreviousRunTimestamp.IsZero() {
+		task = &downloader.Task{
+			Collection: r.name,
+			Request: &api.Request{
+				Url:           "/v1/payment_links?limit=100",
+				LogCollection: r.name,
+			},
+			Output: r.objs,
+			Errors: r.errs,
+		}
+	} else {
+		task = tasks.MakeIncremental(r, r.name, runContext.PreviousRunTimestamp, r.objs, r.errs)
+	}
+
+	return downloader.New(r.apiClient).Do(ctx, task)
+}
+
+func (r *PaymentLink) StartConsumer(ctx context.Context, ch <-chan api.Object) {
+	defer close(r.msgs)
+	for obj := range ch {
+		switch tr.GetString(obj, "object") {
+		case "event":
+			if payload := tr.ExtractEventPayload(obj, "payment_link"); payload != nil {
+				r.consumePaymentLink(payload, true)
+			}
+		case "payment_link":
+			r.consumePaymentLink(obj, false)
+		}
+	}
+}
+
+func (r *PaymentLink) consumePaymentLink(obj api.Object, fromEvent bool) {
+	if msg := r.transform(obj); msg != nil && !(fromEvent && r.dedupe.SeenBefore(msg.ID)) {
+		r.msgs <- *msg
+	}
+}
+
+func (r *PaymentLink) transform(obj api.Object) *source.SetMessage {
+	var id string
+	if id = tr.GetString(obj, "id"); id == "" {
+		return nil
+	}
+
+	properties := map[string]interface{}{
+		"active":                     obj["active"],
+		"allow_promotion_codes":      obj["allow_promotion_codes"],
+		"billing_address_collection": obj["billing_address_collection"],
+		"currency":                   obj["currency"],
+		"url":                        obj["url"],
+	}
+
+	tr.Flatten(tr.GetMap(obj, "metadata"), "metadata_", properties)
+
+	return &source.SetMessage{
+		ID:         id,
+		Collection: r.name,
+		Properties: properties,
+	}
+}
+
+func (r *PaymentLink) Collection() string {
+	return r.name
+}
+
+func (r *PaymentLink) Objects() <-chan api.Object {
+	return r.objs
+}
+
+func (r *PaymentLink) Messages() <-chan source.SetMessage {
+	return r.msgs
+}
+
+func (r *PaymentLink) CollectionErrors() <-chan integration.CollectionError {
+	return r.errs
+}
+
+func (r *PaymentLink) Consumers() []integration.Consumer {
+	return []integration.Consumer{r}
+}
+
+func (r *PaymentLink) Close() {
+	r.dedupe.Close()
+}
+
+func NewPaymentLink(apiClient api.Client) *PaymentLink {
+	return &PaymentLink{
+		name:      "payment_links",
+		apiClient: apiClient,
+		objs:      make(chan api.Object, 1000),
+		msgs:      make(chan source.SetMessage),
+		errs:      make(chan integration.CollectionError),
+		dedupe:    dedupe.New(),
+	}
+}
@@ -0,0 +1,96 @@
+package probe
+
+import (
+	"context"
+	"github.com/apex/log"
+	"github.com/segment-sources/stripe/api"
+	"github.com/segment-sources/stripe/integration"
+	"github.com/segment-sources/stripe/resource/downloader"
+	"github.com/segment-sources/stripe/resource/tasks"
+	"github.com/segmentio/go-source"
+	"net/url"
+	"sort"
+)
+
+// Prober checks whether the API key is allowed to read resources before they're registered.
+// Restricted keys (rk_live_...) only grant access to some endpoints, requests to the others are rejected with 403.
+type Prober struct {
+	apiClient    api.Client
+	sourceClient source.Client
+	granted      []string
+	denied       []string
+}
+
+// Permitted requests a single object from the list endpoint of every resource and returns the resources
+// the API key is allowed to read. Denied resources are closed and their collections reported with a warning.
+// Resources without a list endpoint are derived from other resources' objects and are always permitted.
+func (p *Prober) Permitted(ctx context.Context, resources ...integration.Resource) []integration.Resource {
+	permitted := []integration.Resource{}
+	for _, res := range resources {
+		collections := []string{}
+		for _, con := range res.Consumers() {
+			collections = append(collections, con.Collection())
+		}
+
+		if p.isDenied(ctx, res, collections[0]) {
+			for _, collection := range collections {
+				p.sourceClient.ReportWarning(
+					"The API key doesn't have permission to read this collection, it will not be synced",
+					collection,
+				)
+			}
+			p.denied = append(p.denied, collections...)
+			res.Close()
+			continue
+		}
+
+		p.granted = append(p.granted, collections...)
+		permitted = append(permitted, res)
+	}
+
+	return permitted
+}
+
+func (p *Prober) isDenied(ctx context.Context, res integration.Resource, collection string) bool {
+	i, ok := res.(tasks.HasListEndpoint)
+	if !ok {
+		return false
+	}
+
+	_, err := downloader.RetryGetList(ctx, p.apiClient, &api.Request{
+		Url: i.ListEndpoint(),
+		Qs: url.Values{
+			"limit": []string{"1"},
+		},
+		LogCollection: collection,
+	})
+	if err == nil {
+		return false
+	}
+
+	// other failures are left to the producer, which reports them as collection errors
+	if !api.IsErrorPermissionRelated(err) {
+		log.WithError(err).WithField("endpoint", i.ListEndpoint()).Warn("permission probe failed")
+		return false
+	}
+
+	log.WithField("endpoint", i.ListEndpoint()).Warn("permission denied, disabling collections")
+	return true
+}
+
+// LogSummary logs the collections that were granted and denied so far
+func (p *Prober) LogSummary() {
+	sort.Strings(p.granted)
+	sort.Strings(p.denied)
+	log.WithFields(log.Fields{
+		"granted": p.granted,
+		"denied":  p.denied,
+	}).Info("collection permissions probed")
+}
+
+func New(apiClient api.Client, sourceClient source.Client) *Prober {
+	return &Prober{
+		apiClient:    apiClient,
+		sourceClient: sourceClient,
+	}
+}
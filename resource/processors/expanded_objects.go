@@ -0,0 +1,19 @@
+package processors
+
+import (
+	"context"
+	"github.com/segment-sources/stripe/api"
+	"github.com/segment-sources/stripe/resource/downloader"
+	"github.com/segment-sources/stripe/resource/tr"
+)
+
+// NewExpandedObjects returns a post-processor that sends the object expanded under the given key to the task's
+// output, so that it's consumed as if it was downloaded separately (e.g. balance transactions of charges)
+func NewExpandedObjects(key string) downloader.PostProcessor {
+	return func(ctx context.Context, obj api.Object, task *downloader.Task) error {
+		if expanded := tr.GetMap(obj, key); expanded != nil && tr.GetString(expanded, "object") != "" {
+			task.Output <- expanded
+		}
+		return nil
+	}
+}
@@ -0,0 +1,35 @@
+package processors
+
+import (
+	"context"
+	"github.com/segment-sources/stripe/api"
+	"github.com/segment-sources/stripe/resource/downloader"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestExpandedObjects(t *testing.T) {
+	proc := NewExpandedObjects("balance_transaction")
+	task := &downloader.Task{Output: make(chan api.Object, 2)}
+
+	tx := map[string]interface{}{
+		"id":     "txn_1",
+		"object": "balance_transaction",
+	}
+	charges := []api.Object{
+		{"id": "ch_1", "object": "charge", "balance_transaction": tx},
+		{"id": "ch_2", "object": "charge", "balance_transaction": "txn_2"},
+	}
+
+	a := assert.New(t)
+	for _, charge := range charges {
+		a.NoError(proc(context.Background(), charge, task))
+	}
+	close(task.Output)
+
+	output := []api.Object{}
+	for obj := range task.Output {
+		output = append(output, obj)
+	}
+	a.Equal([]api.Object{tx}, output)
+}
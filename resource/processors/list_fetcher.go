@@ -0,0 +1,80 @@
+package processors
+
+import (
+	"context"
+	"fmt"
+	"github.com/segment-sources/stripe/api"
+	"github.com/segment-sources/stripe/resource/downloader"
+	"github.com/segment-sources/stripe/resource/tr"
+	"net/url"
+	"sync"
+)
+
+// NewListFetcher returns a post-processor that downloads a list which is not embedded in objectType objects
+// (e.g. checkout session line items) and stores it under the given key in the same shape as an embedded list.
+// endpoint is a format string that receives the object's id. Both plain objects and event payloads are processed.
+func NewListFetcher(objectType string, key string, endpoint string, apiClient api.Client) downloader.PostProcessor {
+	d := downloader.New(apiClient)
+
+	return func(ctx context.Context, obj api.Object, task *downloader.Task) error {
+		target := obj
+		if tr.GetString(obj, "object") == "event" {
+			target = tr.ExtractEventPayload(obj)
+		}
+		if target == nil || tr.GetString(target, "object") != objectType {
+			return nil
+		}
+		// lists of deleted objects can't be requested anymore
+		if tr.GetBool(target, "is_deleted") || tr.GetBool(target, "deleted") {
+			return nil
+		}
+		return fetchList(ctx, d, target, task, key, endpoint)
+	}
+}
+
+// fetchList is a post-processor that downloads every page of a list belonging to an object
+// and sets it as the object's key property
+func fetchList(ctx context.Context, d *downloader.Client, obj api.Object, task *downloader.Task, key string, endpoint string) error {
+	var id string
+	if id = tr.GetString(obj, "id"); id == "" {
+		return nil
+	}
+
+	listUrl := fmt.Sprintf(endpoint, id)
+	data := []interface{}{}
+
+	ch := make(chan api.Object)
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for item := range ch {
+			data = append(data, map[string]interface{}(item))
+		}
+	}()
+
+	err := d.Do(ctx, &downloader.Task{
+		Request: &api.Request{
+			Url: listUrl,
+			Qs: url.Values{
+				"limit": []string{"100"},
+			},
+			LogCollection: task.Collection,
+		},
+		Output: ch,
+	})
+
+	close(ch)
+	wg.Wait()
+
+	if err == nil {
+		obj[key] = map[string]interface{}{
+			"object":   "list",
+			"data":     data,
+			"has_more": false,
+			"url":      listUrl,
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,75 @@
+package processors
+
+import (
+	"context"
+	"github.com/segment-sources/stripe/api"
+	"github.com/segment-sources/stripe/resource/downloader"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestListFetcher(t *testing.T) {
+	client := &MockClient{GetListPayloads: map[string]*api.ObjectList{
+		"/v1/checkout/sessions/cs_1/line_items?limit=100": {
+			Objects: []api.Object{
+				{
+					"id":     "li_1",
+					"object": "item",
+				},
+			},
+			HasMore: true,
+		},
+		"/v1/checkout/sessions/cs_1/line_items?limit=100&starting_after=li_1": {
+			Objects: []api.Object{
+				{
+					"id":     "li_2",
+					"object": "item",
+				},
+			},
+			HasMore: false,
+		},
+	}}
+
+	proc := NewListFetcher("checkout.session", "line_items", "/v1/checkout/sessions/%s/line_items", client)
+	session := map[string]interface{}{
+		"id":     "cs_1",
+		"object": "checkout.session",
+	}
+	event := api.Object{
+		"id":     "evt_1",
+		"object": "event",
+		"type":   "checkout.session.completed",
+		"data": map[string]interface{}{
+			"object": session,
+		},
+	}
+
+	a := assert.New(t)
+
+	err := proc(context.Background(), event, &downloader.Task{Collection: "checkout_sessions"})
+	if !a.NoError(err) {
+		return
+	}
+
+	expected := map[string]interface{}{
+		"id":     "cs_1",
+		"object": "checkout.session",
+		"line_items": map[string]interface{}{
+			"object": "list",
+			"data": []interface{}{
+				map[string]interface{}{
+					"id":     "li_1",
+					"object": "item",
+				},
+				map[string]interface{}{
+					"id":     "li_2",
+					"object": "item",
+				},
+			},
+			"has_more": false,
+			"url":      "/v1/checkout/sessions/cs_1/line_items",
+		},
+	}
+
+	a.Equal(expected, session)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/segment-sources/stripe/api"
+	"io"
 	"net/url"
 )
 
@@ -33,3 +34,15 @@ func (c *MockClient) GetList(ctx context.Context, req *api.Request) (*api.Object
 func (c *MockClient) GetObject(context.Context, *api.Request) (api.Object, error) {
 	panic("implement me")
 }
+
+func (c *MockClient) Post(context.Context, *api.Request) (api.Object, error) {
+	panic("implement me")
+}
+
+func (c *MockClient) Delete(context.Context, *api.Request) (api.Object, error) {
+	panic("implement me")
+}
+
+func (c *MockClient) StreamFile(context.Context, *api.Request, func(io.Reader) error) error {
+	panic("implement me")
+}
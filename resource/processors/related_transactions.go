@@ -23,13 +23,21 @@ func NewRelatedTransactions(apiClient api.Client) downloader.PostProcessor {
 
 func NewRelatedTransactionsFromEvents(apiClient api.Client, dd dedupe.Interface) downloader.PostProcessor {
 	dl := downloader.New(apiClient)
+	// events are post-processed concurrently, SeenBefore checks and marks the id in two steps
+	mu := sync.Mutex{}
+	seenBefore := func(id string) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return dd.SeenBefore(id)
+	}
+
 	return func(ctx context.Context, obj api.Object, task *downloader.Task) error {
 		transfer := tr.ExtractEventPayload(obj, "transfer")
 		if transfer == nil {
 			return nil
 		}
 
-		if id := tr.GetString(transfer, "id"); id == "" || dd.SeenBefore(id) {
+		if id := tr.GetString(transfer, "id"); id == "" || seenBefore(id) {
 			return nil
 		}
 
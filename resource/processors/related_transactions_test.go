@@ -0,0 +1,63 @@
+package processors
+
+import (
+	"context"
+	"github.com/segment-sources/stripe/api"
+	"github.com/segment-sources/stripe/resource/downloader"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+// slowDedupe checks and marks ids in two steps like the leveldb store, with a longer gap between them
+type slowDedupe struct {
+	seen sync.Map
+}
+
+func (d *slowDedupe) SeenBefore(id string) bool {
+	if _, ok := d.seen.Load(id); ok {
+		return true
+	}
+	time.Sleep(5 * time.Millisecond)
+	d.seen.Store(id, true)
+	return false
+}
+
+func (d *slowDedupe) Close() {}
+
+func TestRelatedTransactionsFromConcurrentEvents(t *testing.T) {
+	client := &MockClient{GetListPayloads: map[string]*api.ObjectList{
+		"/v1/balance/history?limit=100&transfer=tr_1": {
+			Objects: []api.Object{{"id": "txn_1", "object": "balance_transaction"}},
+		},
+	}}
+	proc := NewRelatedTransactionsFromEvents(client, &slowDedupe{})
+	output := make(chan api.Object, 20)
+	task := &downloader.Task{Output: output}
+
+	wg := sync.WaitGroup{}
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- proc(context.Background(), api.Object{
+				"object": "event",
+				"data": map[string]interface{}{
+					"object": map[string]interface{}{"id": "tr_1", "object": "transfer"},
+				},
+			}, task)
+		}()
+	}
+	wg.Wait()
+	close(output)
+	close(errs)
+
+	a := assert.New(t)
+	for err := range errs {
+		a.NoError(err)
+	}
+	a.Len(output, 1)
+	a.Equal("tr_1", (<-output)["transfer_id"])
+}
@@ -26,6 +26,7 @@ type Product struct {
 	msgs      chan source.SetMessage
 	errs      chan integration.CollectionError
 	dedupe    dedupe.Interface
+	search    bool
 }
 
 func (r *Product) DesiredObjects() []string {
@@ -36,25 +37,46 @@ func (r *Product) DesiredEvents() []string {
 	return productEvents
 }
 
+func (r *Product) ListEndpoint() string {
+	return "/v1/products"
+}
+
+func (r *Product) Schedule() integration.Schedule {
+	return integration.Schedule{Priority: referencePriority}
+}
+
+func (r *Product) ListTask() *downloader.Task {
+	return &downloader.Task{
+		Collection: r.name,
+		Request: &api.Request{
+			Url:           "/v1/products?limit=100",
+			LogCollection: r.name,
+		},
+		Output: r.objs,
+		Errors: r.errs,
+	}
+}
+
 func (r *Product) StartProducer(ctx context.Context, runContext integration.RunContext) error {
 	defer close(r.objs)
 	defer close(r.errs)
 	var task *downloader.Task
 	if runContext.PreviousRunTimestamp.IsZero() {
-		task = &downloader.Task{
-			Collection: r.name,
-			Request: &api.Request{
-				Url:           "/v1/products?limit=100",
-				LogCollection: r.name,
-			},
-			Output: r.objs,
-			Errors: r.errs,
+		task = r.ListTask()
+		if r.search && !runContext.StaleRunTimestamp.IsZero() {
+			return searchIncremental(ctx, r.apiClient, r, task, "/v1/products/search", runContext.StaleRunTimestamp)
+		}
+		if !runContext.StaleRunTimestamp.IsZero() {
+			return partialResync(ctx, r.apiClient, r, task, runContext.StaleRunTimestamp)
 		}
 	} else {
 		task = tasks.MakeIncremental(r, r.name, runContext.PreviousRunTimestamp, r.objs, r.errs)
 	}
 
-	return downloader.New(r.apiClient).Do(ctx, task)
+	if err := downloader.New(r.apiClient).Do(ctx, task); err != nil {
+		return err
+	}
+	return backfill(ctx, r.apiClient, r.ListTask(), runContext)
 }
 
 func (r *Product) GetEventProcessors() []downloader.PostProcessor {
@@ -147,7 +169,8 @@ func (r *Product) Close() {
 	r.dedupe.Close()
 }
 
-func NewProduct(apiClient api.Client) *Product {
+// NewProduct searches for products created since a stale previous run instead of a full sync if search is set
+func NewProduct(apiClient api.Client, search bool) *Product {
 	return &Product{
 		name:      "products",
 		apiClient: apiClient,
@@ -155,5 +178,6 @@ func NewProduct(apiClient api.Client) *Product {
 		msgs:      make(chan source.SetMessage),
 		errs:      make(chan integration.CollectionError),
 		dedupe:    dedupe.New(),
+		search:    search,
 	}
 }
@@ -0,0 +1,171 @@
+package resource
+
+import (
+	"context"
+	"github.com/segment-sources/stripe/api"
+	"github.com/segment-sources/stripe/integration"
+	"github.com/segment-sources/stripe/resource/dedupe"
+	"github.com/segment-sources/stripe/resource/downloader"
+	"github.com/segment-sources/stripe/resource/processors"
+	"github.com/segment-sources/stripe/resource/tr"
+	"github.com/segmentio/go-source"
+)
+
+var quoteEvents = []string{
+	"quote.accepted",
+	"quote.canceled",
+	"quote.created",
+	"quote.finalized",
+}
+
+type Quote struct {
+	name      string
+	apiClient api.Client
+	objs      chan api.Object
+	msgs      chan source.SetMessage
+	errs      chan integration.CollectionError
+	dedupe    dedupe.Interface
+}
+
+func (r *Quote) DesiredObjects() []string {
+	return []string{"quote"}
+}
+
+func (r *Quote) DesiredEvents() []string {
+	return quoteEvents
+}
+
+func (r *Quote) ListEndpoint() string {
+	return "/v1/quotes"
+}
+
+func (r *Quote) StartProducer(ctx context.Context, runContext integration.RunContext) error {
+	defer close(r.objs)
+	defer close(r.errs)
+	if runContext.PreviousRunTimestamp.IsZero() {
+		return downloader.New(r.apiClient).Do(ctx, &downloader.Task{
+			Collection: r.name,
+			Request: &api.Request{
+				Url:           "/v1/quotes?limit=100",
+				LogCollection: r.name,
+			},
+			Output: r.objs,
+			Errors: r.errs,
+			PostProcessors: []downloader.PostProcessor{
+				r.newLineItemsFetcher(),
+			},
+		})
+	}
+
+	// downloading events in incremental mode is handled by the bundle that this resource is a part of
+	return nil
+}
+
+func (r *Quote) GetEventProcessors() []downloader.PostProcessor {
+	return []downloader.PostProcessor{
+		r.newLineItemsFetcher(),
+	}
+}
+
+// line items aren't embedded in quotes and have to be requested for every quote separately
+func (r *Quote) newLineItemsFetcher() downloader.PostProcessor {
+	return processors.NewListFetcher("quote", "line_items", "/v1/quotes/%s/line_items", r.apiClient)
+}
+
+func (r *Quote) StartConsumer(ctx context.Context, ch <-chan api.Object) {
+	defer close(r.msgs)
+	for obj := range ch {
+		switch tr.GetString(obj, "object") {
+		case "event":
+			if payload := tr.ExtractEventPayload(obj, "quote"); payload != nil {
+				r.consumeQuote(payload, true)
+			}
+		case "quote":
+			r.consumeQuote(obj, false)
+		}
+	}
+}
+
+func (r *Quote) consumeQuote(obj api.Object, fromEvent bool) {
+	if msg := r.transform(obj); msg != nil && !(fromEvent && r.dedupe.SeenBefore(msg.ID)) {
+		r.msgs <- *msg
+	}
+}
+
+func (r *Quote) transform(obj api.Object) *source.SetMessage {
+	var id string
+	if id = tr.GetString(obj, "id"); id == "" {
+		return nil
+	}
+
+	properties := map[string]interface{}{
+		"amount_subtotal":   obj["amount_subtotal"],
+		"amount_total":      obj["amount_total"],
+		"collection_method": obj["collection_method"],
+		"currency":          obj["currency"],
+		"customer_id":       tr.GetId(obj, "customer"),
+		"description":       obj["description"],
+		"invoice_id":        tr.GetId(obj, "invoice"),
+		"number":            obj["number"],
+		"status":            obj["status"],
+		"subscription_id":   tr.GetId(obj, "subscription"),
+	}
+
+	tr.Flatten(tr.GetMap(obj, "metadata"), "metadata_", properties)
+
+	if transitions := tr.GetMap(obj, "status_transitions"); transitions != nil {
+		for _, key := range []string{"accepted_at", "canceled_at", "finalized_at"} {
+			if ts := tr.GetTimestamp(transitions, key); ts != "" {
+				properties[key] = ts
+			}
+		}
+	}
+
+	if created := tr.GetTimestamp(obj, "created"); created != "" {
+		properties["created"] = created
+	}
+	if expiresAt := tr.GetTimestamp(obj, "expires_at"); expiresAt != "" {
+		properties["expires_at"] = expiresAt
+	}
+
+	return &source.SetMessage{
+		ID:         id,
+		Collection: r.name,
+		Properties: properties,
+	}
+}
+
+func (r *Quote) Collection() string {
+	return r.name
+}
+
+func (r *Quote) Objects() <-chan api.Object {
+	return r.objs
+}
+
+func (r *Quote) Messages() <-chan source.SetMessage {
+	return r.msgs
+}
+
+func (r *Quote) CollectionErrors() <-chan integration.CollectionError {
+	return r.errs
+}
+
+func (r *Quote) Consumers() []integration.Consumer {
+	return []integration.Consumer{r}
+}
+
+func (r *Quote) Close() {
+	r.dedupe.Close()
+}
+
+func NewQuote(apiClient api.Client) *Quote {
+	return &Quote{
+		name:      "quotes",
+		apiClient: apiClient,
+		objs:      make(chan api.Object, 1000),
+		msgs:      make(chan source.SetMessage),
+		errs:      make(chan integration.CollectionError),
+		dedupe:    dedupe.New(),
+	}
+}
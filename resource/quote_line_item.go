@@ -0,0 +1,140 @@
+package resource
+
+import (
+	"context"
+	"crypto/md5"
+	"fmt"
+	"github.com/segment-sources/stripe/api"
+	"github.com/segment-sources/stripe/integration"
+	"github.com/segment-sources/stripe/resource/dedupe"
+	"github.com/segment-sources/stripe/resource/tr"
+	"github.com/segmentio/go-source"
+	"strings"
+)
+
+type QuoteLineItem struct {
+	name      string
+	apiClient api.Client
+	objs      chan api.Object
+	msgs      chan source.SetMessage
+	errs      chan integration.CollectionError
+	dedupe    dedupe.Interface
+}
+
+func (r *QuoteLineItem) DesiredObjects() []string {
+	return []string{"quote"}
+}
+
+func (r *QuoteLineItem) DesiredEvents() []string {
+	return quoteEvents
+}
+
+func (r *QuoteLineItem) StartProducer(ctx context.Context, runContext integration.RunContext) error {
+	// downloading events in incremental mode is handled by the bundle that this resource is a part of
+	close(r.objs)
+	close(r.errs)
+	return nil
+}
+
+func (r *QuoteLineItem) StartConsumer(ctx context.Context, ch <-chan api.Object) {
+	defer close(r.msgs)
+	for obj := range ch {
+		switch tr.GetString(obj, "object") {
+		case "event":
+			if payload := tr.ExtractEventPayload(obj, "quote"); payload != nil {
+				r.consumeQuote(payload, true)
+			}
+		case "quote":
+			r.consumeQuote(obj, false)
+		}
+	}
+}
+
+func (r *QuoteLineItem) consumeQuote(obj api.Object, fromEvent bool) {
+	var quoteId string
+	if quoteId = tr.GetString(obj, "id"); quoteId == "" || fromEvent && r.dedupe.SeenBefore(quoteId) {
+		return
+	}
+
+	for _, item := range tr.GetMapList(tr.GetMap(obj, "line_items"), "data") {
+		if msg := r.transform(obj, item); msg != nil {
+			r.msgs <- *msg
+		}
+	}
+}
+
+func (r *QuoteLineItem) transform(quote, item api.Object) *source.SetMessage {
+	var itemId string
+	if itemId = tr.GetString(item, "id"); itemId == "" {
+		return nil
+	}
+	var quoteId string
+	if quoteId = tr.GetString(quote, "id"); quoteId == "" {
+		return nil
+	}
+
+	properties := map[string]interface{}{
+		"amount_discount": item["amount_discount"],
+		"amount_subtotal": item["amount_subtotal"],
+		"amount_tax":      item["amount_tax"],
+		"amount_total":    item["amount_total"],
+		"currency":        item["currency"],
+		"description":     item["description"],
+		"item_id":         itemId,
+		"quantity":        item["quantity"],
+		"quote_id":        quoteId,
+	}
+
+	if price := tr.GetMap(item, "price"); price != nil {
+		if priceId := tr.GetString(price, "id"); priceId != "" {
+			properties["price_id"] = priceId
+		}
+		if productId := tr.GetString(price, "product"); productId != "" {
+			properties["product_id"] = productId
+		}
+	}
+
+	hash := md5.New()
+	fmt.Fprint(hash, strings.Join([]string{quoteId, itemId}, ", "))
+
+	return &source.SetMessage{
+		ID:         fmt.Sprintf("%x", hash.Sum(nil)),
+		Collection: r.name,
+		Properties: properties,
+	}
+}
+
+func (r *QuoteLineItem) Collection() string {
+	return r.name
+}
+
+func (r *QuoteLineItem) Objects() <-chan api.Object {
+	return r.objs
+}
+
+func (r *QuoteLineItem) Messages() <-chan source.SetMessage {
+	return r.msgs
+}
+
+func (r *QuoteLineItem) CollectionErrors() <-chan integration.CollectionError {
+	return r.errs
+}
+
+func (r *QuoteLineItem) Consumers() []integration.Consumer {
+	return []integration.Consumer{r}
+}
+
+func (r *QuoteLineItem) Close() {
+	r.dedupe.Close()
+}
+
+func NewQuoteLineItem(apiClient api.Client) *QuoteLineItem {
+	return &QuoteLineItem{
+		name:      "quote_line_items",
+		apiClient: apiClient,
+		objs:      make(chan api.Object, 1000),
+		msgs:      make(chan source.SetMessage),
+		errs:      make(chan integration.CollectionError),
+		dedupe:    dedupe.New(),
+	}
+}
@@ -0,0 +1,121 @@
+package resource
+
+import (
+	"context"
+	"github.com/segment-sources/stripe/api"
+	"github.com/segment-sources/stripe/integration"
+	"github.com/segment-sources/stripe/resource/dedupe"
+	"github.com/segment-sources/stripe/resource/downloader"
+	"github.com/segment-sources/stripe/resource/processors"
+	"github.com/segment-sources/stripe/resource/tr"
+	"github.com/segmentio/go-source"
+)
+
+type RadarValueList struct {
+	name      string
+	apiClient api.Client
+	objs      chan api.Object
+	msgs      chan source.SetMessage
+	errs      chan integration.CollectionError
+	dedupe    dedupe.Interface
+}
+
+func (r *RadarValueList) DesiredObjects() []string {
+	return []string{"radar.value_list"}
+}
+
+func (r *RadarValueList) DesiredEvents() []string {
+	return nil
+}
+
+func (r *RadarValueList) ListEndpoint() string {
+	return "/v1/radar/value_lists"
+}
+
+// StartProducer for RadarValueList always performs a full sync since Stripe doesn't emit value list events
+func (r *RadarValueList) StartProducer(ctx context.Context, runContext integration.RunContext) error {
+	defer close(r.objs)
+	defer close(r.errs)
+	return downloader.New(r.apiClient).Do(ctx, &downloader.Task{
+		Collection: r.name,
+		Request: &api.Request{
+			Url:           "/v1/radar/value_lists?limit=100",
+			LogCollection: r.name,
+		},
+		Output: r.objs,
+		Errors: r.errs,
+		PostProcessors: []downloader.PostProcessor{
+			processors.NewListExpander("list_items", r.apiClient),
+		},
+	})
+}
+
+func (r *RadarValueList) StartConsumer(ctx context.Context, ch <-chan api.Object) {
+	defer close(r.msgs)
+	for obj := range ch {
+		if msg := r.transform(obj); msg != nil {
+			r.msgs <- *msg
+		}
+	}
+}
+
+func (r *RadarValueList) transform(obj api.Object) *source.SetMessage {
+	var id string
+	if id = tr.GetString(obj, "id"); id == "" {
+		return nil
+	}
+
+	properties := map[string]interface{}{
+		"alias":      obj["alias"],
+		"created_by": obj["created_by"],
+		"item_type":  obj["item_type"],
+		"name":       obj["name"],
+	}
+
+	tr.Flatten(tr.GetMap(obj, "metadata"), "metadata_", properties)
+
+	if created := tr.GetTimestamp(obj, "created"); created != "" {
+		properties["created"] = created
+	}
+
+	return &source.SetMessage{
+		ID:         id,
+		Collection: r.name,
+		Properties: properties,
+	}
+}
+
+func (r *RadarValueList) Collection() string {
+	return r.name
+}
+
+func (r *RadarValueList) Objects() <-chan api.Object {
+	return r.objs
+}
+
+func (r *RadarValueList) Messages() <-chan source.SetMessage {
+	return r.msgs
+}
+
+func (r *RadarValueList) CollectionErrors() <-chan integration.CollectionError {
+	return r.errs
+}
+
+func (r *RadarValueList) Consumers() []integration.Consumer {
+	return []integration.Consumer{r}
+}
+
+func (r *RadarValueList) Close() {
+	r.dedupe.Close()
+}
+
+func NewRadarValueList(apiClient api.Client) *RadarValueList {
+	return &RadarValueList{
+		name:      "radar_value_lists",
+		apiClient: apiClient,
+		objs:      make(chan api.Object, 1000),
+		msgs:      make(chan source.SetMessage),
+		errs:      make(chan integration.CollectionError),
+		dedupe:    dedupe.New(),
+	}
+}
@@ -0,0 +1,109 @@
+package resource
+
+import (
+	"context"
+	"github.com/segment-sources/stripe/api"
+	"github.com/segment-sources/stripe/integration"
+	"github.com/segment-sources/stripe/resource/dedupe"
+	"github.com/segment-sources/stripe/resource/tr"
+	"github.com/segmentio/go-source"
+)
+
+type RadarValueListItem struct {
+	name      string
+	apiClient api.Client
+	objs      chan api.Object
+	msgs      chan source.SetMessage
+	errs      chan integration.CollectionError
+	dedupe    dedupe.Interface
+}
+
+func (r *RadarValueListItem) DesiredObjects() []string {
+	return []string{"radar.value_list"}
+}
+
+func (r *RadarValueListItem) DesiredEvents() []string {
+	return nil
+}
+
+func (r *RadarValueListItem) StartProducer(ctx context.Context, runContext integration.RunContext) error {
+	// value list items are embedded in the value lists downloaded by RadarValueList
+	close(r.objs)
+	close(r.errs)
+	return nil
+}
+
+func (r *RadarValueListItem) StartConsumer(ctx context.Context, ch <-chan api.Object) {
+	defer close(r.msgs)
+	for obj := range ch {
+		if tr.GetString(obj, "object") == "radar.value_list" {
+			r.consumeValueList(obj)
+		}
+	}
+}
+
+func (r *RadarValueListItem) consumeValueList(obj api.Object) {
+	for _, item := range tr.GetMapList(tr.GetMap(obj, "list_items"), "data") {
+		if msg := r.transform(item); msg != nil {
+			r.msgs <- *msg
+		}
+	}
+}
+
+func (r *RadarValueListItem) transform(obj api.Object) *source.SetMessage {
+	var id string
+	if id = tr.GetString(obj, "id"); id == "" {
+		return nil
+	}
+
+	properties := map[string]interface{}{
+		"created_by":    obj["created_by"],
+		"value":         obj["value"],
+		"value_list_id": obj["value_list"],
+	}
+
+	if created := tr.GetTimestamp(obj, "created"); created != "" {
+		properties["created"] = created
+	}
+
+	return &source.SetMessage{
+		ID:         id,
+		Collection: r.name,
+		Properties: properties,
+	}
+}
+
+func (r *RadarValueListItem) Collection() string {
+	return r.name
+}
+
+func (r *RadarValueListItem) Objects() <-chan api.Object {
+	return r.objs
+}
+
+func (r *RadarValueListItem) Messages() <-chan source.SetMessage {
+	return r.msgs
+}
+
+func (r *RadarValueListItem) CollectionErrors() <-chan integration.CollectionError {
+	return r.errs
+}
+
+func (r *RadarValueListItem) Consumers() []integration.Consumer {
+	return []integration.Consumer{r}
+}
+
+func (r *RadarValueListItem) Close() {
+	r.dedupe.Close()
+}
+
+func NewRadarValueListItem(apiClient api.Client) *RadarValueListItem {
+	return &RadarValueListItem{
+		name:      "radar_value_list_items",
+		apiClient: apiClient,
+		objs:      make(chan api.Object, 1000),
+		msgs:      make(chan source.SetMessage),
+		errs:      make(chan integration.CollectionError),
+		dedupe:    dedupe.New(),
+	}
+}
@@ -31,23 +31,34 @@ func (r *Refund) DesiredEvents() []string {
 	return append(refundEvents, chargeEvents...)
 }
 
+func (r *Refund) ListEndpoint() string {
+	return "/v1/refunds"
+}
+
+func (r *Refund) ListTask() *downloader.Task {
+	return &downloader.Task{
+		Collection: r.name,
+		Request: &api.Request{
+			Url:           "/v1/refunds?limit=100",
+			LogCollection: r.name,
+		},
+		Output: r.objs,
+		Errors: r.errs,
+	}
+}
+
 func (r *Refund) StartProducer(ctx context.Context, runContext integration.RunContext) error {
 	defer close(r.objs)
 	defer close(r.errs)
 	if runContext.PreviousRunTimestamp.IsZero() {
-		return downloader.New(r.apiClient).Do(ctx, &downloader.Task{
-			Collection: r.name,
-			Request: &api.Request{
-				Url:           "/v1/refunds?limit=100",
-				LogCollection: r.name,
-			},
-			Output: r.objs,
-			Errors: r.errs,
-		})
+		if !runContext.StaleRunTimestamp.IsZero() {
+			return partialResync(ctx, r.apiClient, r, r.ListTask(), runContext.StaleRunTimestamp)
+		}
+		return downloader.New(r.apiClient).Do(ctx, r.ListTask())
 	}
 
 	// downloading events in incremental mode is handled by the bundle that this resource is a part of
-	return nil
+	return backfill(ctx, r.apiClient, r.ListTask(), runContext)
 }
 
 func (r *Refund) StartConsumer(ctx context.Context, ch <-chan api.Object) {
@@ -100,8 +111,8 @@ func (r *Refund) transform(obj api.Object) *source.SetMessage {
 	properties := map[string]interface{}{
 		"amount":                 obj["amount"],
 		"currency":               obj["currency"],
-		"balance_transaction_id": obj["balance_transaction"],
-		"charge_id":              obj["charge"],
+		"balance_transaction_id": tr.GetId(obj, "balance_transaction"),
+		"charge_id":              tr.GetId(obj, "charge"),
 		"receipt_number":         obj["receipt_number"],
 		"reason":                 obj["reason"],
 	}
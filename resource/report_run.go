@@ -0,0 +1,294 @@
+package resource
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/csv"
+	"fmt"
+	"github.com/apex/log"
+	"github.com/pkg/errors"
+	"github.com/segment-sources/stripe/api"
+	"github.com/segment-sources/stripe/integration"
+	"github.com/segment-sources/stripe/resource/dedupe"
+	"github.com/segment-sources/stripe/resource/downloader"
+	"github.com/segment-sources/stripe/resource/tr"
+	"github.com/segmentio/go-source"
+	"github.com/segmentio/ur-log"
+	"io"
+	"strings"
+	"time"
+)
+
+var reportRunPollInterval = time.Second * 10
+
+const reportRunTimeout = time.Hour
+
+// ReportRun requests a Stripe report run of a single report type, waits until it's completed
+// and emits every row of the resulting CSV file to a collection of its own
+type ReportRun struct {
+	name       string
+	reportType string
+	interval   time.Duration
+	apiClient  api.Client
+	objs       chan api.Object
+	msgs       chan source.SetMessage
+	errs       chan integration.CollectionError
+	dedupe     dedupe.Interface
+}
+
+func (r *ReportRun) DesiredObjects() []string {
+	return []string{"report_row"}
+}
+
+func (r *ReportRun) DesiredEvents() []string {
+	return nil
+}
+
+func (r *ReportRun) ListEndpoint() string {
+	return "/v1/reporting/report_runs"
+}
+
+// PartialListing is true since rows outside of the report's interval aren't downloaded again
+func (r *ReportRun) PartialListing() bool {
+	return true
+}
+
+// StartProducer for ReportRun always requests a new report run covering the configured interval
+// and ending with the most recent data available for the report type
+func (r *ReportRun) StartProducer(ctx context.Context, runContext integration.RunContext) error {
+	defer close(r.objs)
+	defer close(r.errs)
+
+	ctx, _ = urlog.GetContextualLogger(ctx, nil, log.Fields{
+		"collection":  r.name,
+		"report_type": r.reportType,
+	})
+
+	run, err := r.createRun(ctx)
+	if err == nil {
+		run, err = r.waitForRun(ctx, tr.GetString(run, "id"))
+	}
+	if err != nil {
+		r.errs <- integration.CollectionError{
+			Collection: r.name,
+			Message:    downloader.ErrorMessage(err),
+		}
+		return urlog.WrapError(ctx, err, "failed to run report")
+	}
+
+	if status := tr.GetString(run, "status"); status != "succeeded" {
+		r.errs <- integration.CollectionError{
+			Collection: r.name,
+			Message:    fmt.Sprintf("Report run %s: %s", status, tr.GetString(run, "error")),
+		}
+		return urlog.WrapError(ctx, errors.New("report run didn't succeed"), "")
+	}
+
+	err = downloader.RetryStreamFile(ctx, r.apiClient, &api.Request{
+		Url:           tr.GetString(tr.GetMap(run, "result"), "url"),
+		LogCollection: r.name,
+	}, func(body io.Reader) error {
+		return r.produceRows(run, body)
+	})
+	if _, ok := err.(*csv.ParseError); ok {
+		r.errs <- integration.CollectionError{
+			Collection: r.name,
+			Message:    "Report file could not be parsed",
+		}
+		return urlog.WrapError(ctx, err, "failed to parse report")
+	} else if err != nil {
+		r.errs <- integration.CollectionError{
+			Collection: r.name,
+			Message:    downloader.ErrorMessage(err),
+		}
+		return urlog.WrapError(ctx, err, "failed to download report")
+	}
+
+	return nil
+}
+
+func (r *ReportRun) createRun(ctx context.Context) (api.Object, error) {
+	reportType, err := downloader.RetryGetObject(ctx, r.apiClient, &api.Request{
+		Url:           "/v1/reporting/report_types/" + r.reportType,
+		LogCollection: r.name,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	intervalEnd := tr.GetNumber(reportType, "data_available_end")
+	intervalStart := intervalEnd - int64(r.interval/time.Second)
+	if availableStart := tr.GetNumber(reportType, "data_available_start"); intervalStart < availableStart {
+		intervalStart = availableStart
+	}
+
+	return downloader.RetryPost(ctx, r.apiClient, &api.Request{
+		Url: "/v1/reporting/report_runs",
+		Params: map[string]interface{}{
+			"report_type": r.reportType,
+			"parameters": map[string]interface{}{
+				"interval_start": intervalStart,
+				"interval_end":   intervalEnd,
+			},
+		},
+		LogCollection: r.name,
+	})
+}
+
+// waitForRun polls a report run until it's not pending anymore
+func (r *ReportRun) waitForRun(ctx context.Context, id string) (api.Object, error) {
+	deadline := time.Now().Add(reportRunTimeout)
+	for time.Now().Before(deadline) {
+		timer := time.NewTimer(reportRunPollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+
+		run, err := downloader.RetryGetObject(ctx, r.apiClient, &api.Request{
+			Url:           "/v1/reporting/report_runs/" + id,
+			LogCollection: r.name,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if tr.GetString(run, "status") != "pending" {
+			return run, nil
+		}
+	}
+
+	return nil, errors.Errorf("report run %s is still pending after %s", id, reportRunTimeout.String())
+}
+
+// produceRows reads the CSV file of a report run while it's being downloaded
+func (r *ReportRun) produceRows(run api.Object, body io.Reader) error {
+	reader := csv.NewReader(body)
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		columns := map[string]interface{}{}
+		for i, name := range header {
+			if i < len(record) {
+				columns[name] = record[i]
+			}
+		}
+
+		r.objs <- api.Object{
+			"object":        "report_row",
+			"report_type":   r.reportType,
+			"report_run_id": run["id"],
+			"parameters":    run["parameters"],
+			"columns":       columns,
+			"values":        record,
+		}
+	}
+}
+
+func (r *ReportRun) StartConsumer(ctx context.Context, ch <-chan api.Object) {
+	defer close(r.msgs)
+	for obj := range ch {
+		// rows of all the report types are routed to every report consumer
+		if tr.GetString(obj, "report_type") != r.reportType {
+			continue
+		}
+		if msg := r.transform(obj); msg != nil {
+			r.msgs <- *msg
+		}
+	}
+}
+
+func (r *ReportRun) transform(obj api.Object) *source.SetMessage {
+	columns := tr.GetMap(obj, "columns")
+	if columns == nil {
+		return nil
+	}
+
+	properties := map[string]interface{}{
+		"report_run_id": obj["report_run_id"],
+	}
+	for name, value := range columns {
+		properties[name] = value
+	}
+
+	parameters := tr.GetMap(obj, "parameters")
+	intervalStart := tr.GetTimestamp(parameters, "interval_start")
+	intervalEnd := tr.GetTimestamp(parameters, "interval_end")
+	properties["interval_start"] = intervalStart
+	properties["interval_end"] = intervalEnd
+
+	// itemized reports are keyed by balance transaction so that rows of overlapping runs are merged,
+	// rows of summary reports are only unique within an interval
+	internalIdComponents := []string{r.reportType}
+	if txId := tr.GetString(columns, "balance_transaction_id"); txId != "" {
+		internalIdComponents = append(internalIdComponents, txId)
+	} else {
+		internalIdComponents = append(internalIdComponents, intervalStart, intervalEnd)
+		if values, ok := obj["values"].([]string); ok {
+			internalIdComponents = append(internalIdComponents, values...)
+		}
+	}
+
+	hash := md5.New()
+	fmt.Fprint(hash, strings.Join(internalIdComponents, ", "))
+
+	return &source.SetMessage{
+		ID:         fmt.Sprintf("%x", hash.Sum(nil)),
+		Collection: r.name,
+		Properties: properties,
+	}
+}
+
+func (r *ReportRun) Collection() string {
+	return r.name
+}
+
+func (r *ReportRun) Objects() <-chan api.Object {
+	return r.objs
+}
+
+func (r *ReportRun) Messages() <-chan source.SetMessage {
+	return r.msgs
+}
+
+func (r *ReportRun) CollectionErrors() <-chan integration.CollectionError {
+	return r.errs
+}
+
+func (r *ReportRun) Consumers() []integration.Consumer {
+	return []integration.Consumer{r}
+}
+
+func (r *ReportRun) Close() {
+	r.dedupe.Close()
+}
+
+// NewReportRun creates a resource for a report type such as "balance.summary.1",
+// its rows are stored in a collection named after the type, e.g. "report_balance_summary_1"
+func NewReportRun(apiClient api.Client, reportType string, interval time.Duration) *ReportRun {
+	return &ReportRun{
+		name:       "report_" + strings.Replace(reportType, ".", "_", -1),
+		reportType: reportType,
+		interval:   interval,
+		apiClient:  apiClient,
+		objs:       make(chan api.Object, 1000),
+		msgs:       make(chan source.SetMessage),
+		errs:       make(chan integration.CollectionError),
+		dedupe:     dedupe.New(),
+	}
+}
@@ -0,0 +1,178 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/segment-sources/stripe/api"
+	"github.com/segment-sources/stripe/integration"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// reportClient serves a report type, a run that is pending until it was polled twice and its CSV file
+type reportClient struct {
+	api.Client
+	mu     sync.Mutex
+	params map[string]interface{}
+	polls  int
+	file   string
+}
+
+func (c *reportClient) GetObject(ctx context.Context, req *api.Request) (api.Object, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch req.Url {
+	case "/v1/reporting/report_types/balance.summary.1":
+		return api.Object{
+			"id":                   "balance.summary.1",
+			"object":               "reporting.report_type",
+			"data_available_start": json.Number("1561939200"),
+			"data_available_end":   json.Number("1564617600"),
+		}, nil
+	case "/v1/reporting/report_runs/frr_1":
+		c.polls++
+		run := api.Object{
+			"id":         "frr_1",
+			"object":     "reporting.report_run",
+			"parameters": c.params["parameters"],
+			"status":     "pending",
+		}
+		if c.polls > 1 {
+			run["status"] = "succeeded"
+			run["result"] = map[string]interface{}{"url": "https://files.stripe.com/v1/files/file_1/contents"}
+		}
+		return run, nil
+	}
+	return nil, &api.StripeError{StatusCode: 404}
+}
+
+func (c *reportClient) Post(ctx context.Context, req *api.Request) (api.Object, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.params = req.Params
+	return api.Object{"id": "frr_1", "object": "reporting.report_run", "status": "pending"}, nil
+}
+
+func (c *reportClient) StreamFile(ctx context.Context, req *api.Request, fn func(io.Reader) error) error {
+	return fn(strings.NewReader(c.file))
+}
+
+func runReport(client *reportClient, interval time.Duration) ([]api.Object, []integration.CollectionError, error) {
+	defer func(interval time.Duration) { reportRunPollInterval = interval }(reportRunPollInterval)
+	reportRunPollInterval = time.Millisecond
+
+	r := NewReportRun(client, "balance.summary.1", interval)
+	defer r.Close()
+
+	errs := []integration.CollectionError{}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for err := range r.CollectionErrors() {
+			errs = append(errs, err)
+		}
+	}()
+
+	err := r.StartProducer(context.Background(), integration.RunContext{})
+	<-done
+
+	rows := []api.Object{}
+	for obj := range r.Objects() {
+		rows = append(rows, obj)
+	}
+	return rows, errs, err
+}
+
+func TestReportRun(t *testing.T) {
+	a := assert.New(t)
+	client := &reportClient{
+		file: "category,currency,net_amount\ncharge,usd,1000.00\nrefund,usd,-100.00\n",
+	}
+
+	rows, errs, err := runReport(client, time.Hour*24*7)
+	a.NoError(err)
+	a.Empty(errs)
+
+	// the run ends with the most recent data and covers the interval
+	a.Equal(map[string]interface{}{
+		"interval_start": int64(1564012800),
+		"interval_end":   int64(1564617600),
+	}, client.params["parameters"])
+	a.Equal(2, client.polls)
+
+	if a.Len(rows, 2) {
+		a.Equal("report_row", rows[0]["object"])
+		a.Equal("frr_1", rows[0]["report_run_id"])
+		a.Equal(map[string]interface{}{"category": "charge", "currency": "usd", "net_amount": "1000.00"}, rows[0]["columns"])
+		a.Equal([]string{"refund", "usd", "-100.00"}, rows[1]["values"])
+	}
+}
+
+func TestReportRunIntervalStartsWithAvailableData(t *testing.T) {
+	client := &reportClient{file: ""}
+
+	rows, _, err := runReport(client, time.Hour*24*365)
+	assert.NoError(t, err)
+	assert.Empty(t, rows)
+	assert.Equal(t, int64(1561939200), client.params["parameters"].(map[string]interface{})["interval_start"])
+}
+
+func TestReportRunWithInvalidFile(t *testing.T) {
+	client := &reportClient{file: "category,currency\n\"charge,usd\n"}
+
+	_, errs, err := runReport(client, time.Hour*24)
+	assert.Error(t, err)
+	if assert.Len(t, errs, 1) {
+		assert.Equal(t, "Report file could not be parsed", errs[0].Message)
+	}
+}
+
+func TestReportRowTransform(t *testing.T) {
+	r := NewReportRun(nil, "balance_change_from_activity.itemized.1", time.Hour)
+	defer r.Close()
+
+	msg := r.transform(api.Object{
+		"object":        "report_row",
+		"report_type":   "balance_change_from_activity.itemized.1",
+		"report_run_id": "frr_1",
+		"parameters": map[string]interface{}{
+			"interval_start": json.Number("1561939200"),
+			"interval_end":   json.Number("1564617600"),
+		},
+		"columns": map[string]interface{}{"balance_transaction_id": "txn_1", "gross": "10.00"},
+		"values":  []string{"txn_1", "10.00"},
+	})
+
+	a := assert.New(t)
+	a.Equal("report_balance_change_from_activity_itemized_1", msg.Collection)
+	a.Equal(map[string]interface{}{
+		"balance_transaction_id": "txn_1",
+		"gross":                  "10.00",
+		"report_run_id":          "frr_1",
+		"interval_start":         "2019-07-01T00:00:00.000Z",
+		"interval_end":           "2019-08-01T00:00:00.000Z",
+	}, msg.Properties)
+
+	// rows of itemized reports are keyed by their balance transaction, regardless of the run
+	other := r.transform(api.Object{
+		"report_run_id": "frr_2",
+		"parameters":    map[string]interface{}{"interval_start": json.Number("1561939200")},
+		"columns":       map[string]interface{}{"balance_transaction_id": "txn_1", "gross": "10.00"},
+	})
+	a.Equal(msg.ID, other.ID)
+}
+
+func TestReportRunPollingStopsWithContext(t *testing.T) {
+	r := NewReportRun(&reportClient{}, "balance.summary.1", time.Hour)
+	defer r.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := r.waitForRun(ctx, "frr_1")
+	assert.Equal(t, context.Canceled, err)
+}
@@ -0,0 +1,28 @@
+package resource
+
+import (
+	"context"
+	"github.com/apex/log"
+	"github.com/segment-sources/stripe/api"
+	"github.com/segment-sources/stripe/integration"
+	"github.com/segment-sources/stripe/resource/downloader"
+	"github.com/segment-sources/stripe/resource/tasks"
+	"time"
+)
+
+// partialResync is used instead of a full sync if the run context is stale and the list endpoint supports
+// created filters. Objects created since the previous run are listed again, updates of older objects are
+// received from the events that are still retained.
+func partialResync(ctx context.Context, apiClient api.Client, res integration.Resource, listTask *downloader.Task, previousRunTimestamp time.Time) error {
+	log.WithFields(log.Fields{
+		"collection":             listTask.Collection,
+		"previous_run_timestamp": previousRunTimestamp,
+	}).Info("listing objects created since a stale run instead of a full sync")
+
+	d := downloader.New(apiClient)
+	if err := d.Do(ctx, tasks.CreatedBetween(listTask, previousRunTimestamp.Add(-time.Hour), time.Time{})); err != nil {
+		return err
+	}
+
+	return replayRetainedEvents(ctx, d, res, listTask, previousRunTimestamp)
+}
@@ -0,0 +1,58 @@
+package resource
+
+import (
+	"context"
+	"github.com/segment-sources/stripe/api"
+	"github.com/segment-sources/stripe/integration"
+	"github.com/segment-sources/stripe/resource/bundle"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+// requestClient serves empty lists and records the requests
+type requestClient struct {
+	api.Client
+	mu       sync.Mutex
+	requests []*api.Request
+}
+
+func (c *requestClient) GetList(ctx context.Context, req *api.Request) (*api.ObjectList, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests = append(c.requests, req)
+	return &api.ObjectList{}, nil
+}
+
+func TestStaleBundleReplaysEventsOfDerivedCollections(t *testing.T) {
+	client := &requestClient{}
+	b := bundle.New(client, 0, NewCharge(client, 0, false, false), NewCard(client), NewBankAccount(client))
+	defer b.Close()
+
+	go func() {
+		for range b.Objects() {
+		}
+	}()
+	go func() {
+		for range b.CollectionErrors() {
+		}
+	}()
+	err := b.StartProducer(context.Background(), integration.RunContext{
+		StaleRunTimestamp: time.Now().Add(-time.Hour * 24 * 60),
+	})
+
+	a := assert.New(t)
+	a.NoError(err)
+	events := []*api.Request{}
+	for _, req := range client.requests {
+		if req.Url == "/v1/events" {
+			events = append(events, req)
+		}
+	}
+	// members don't replay events separately, cards are updated by the events of the bundle
+	if a.Len(events, 1) {
+		a.Contains(events[0].Qs["types[]"], "customer.source.updated")
+		a.Contains(events[0].Qs["types[]"], "charge.updated")
+	}
+}
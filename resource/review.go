@@ -0,0 +1,153 @@
+package resource
+
+import (
+	"context"
+	"github.com/segment-sources/stripe/api"
+	"github.com/segment-sources/stripe/integration"
+	"github.com/segment-sources/stripe/resource/dedupe"
+	"github.com/segment-sources/stripe/resource/downloader"
+	"github.com/segment-sources/stripe/resource/tr"
+	"github.com/segmentio/go-source"
+)
+
+var reviewEvents = []string{
+	"review.closed",
+	"review.opened",
+}
+
+type Review struct {
+	name      string
+	apiClient api.Client
+	objs      chan api.Object
+	msgs      chan source.SetMessage
+	errs      chan integration.CollectionError
+	dedupe    dedupe.Interface
+}
+
+func (r *Review) DesiredObjects() []string {
+	return []string{"review"}
+}
+
+func (r *Review) DesiredEvents() []string {
+	return reviewEvents
+}
+
+func (r *Review) ListEndpoint() string {
+	return "/v1/reviews"
+}
+
+// PartialListing is true since only open reviews are listed, closed reviews aren't deleted
+func (r *Review) PartialListing() bool {
+	return true
+}
+
+func (r *Review) ListTask() *downloader.Task {
+	return &downloader.Task{
+		Collection: r.name,
+		Request: &api.Request{
+			Url:           "/v1/reviews?limit=100",
+			LogCollection: r.name,
+		},
+		Output: r.objs,
+		Errors: r.errs,
+	}
+}
+
+func (r *Review) StartProducer(ctx context.Context, runContext integration.RunContext) error {
+	defer close(r.objs)
+	defer close(r.errs)
+	if runContext.PreviousRunTimestamp.IsZero() {
+		if !runContext.StaleRunTimestamp.IsZero() {
+			return partialResync(ctx, r.apiClient, r, r.ListTask(), runContext.StaleRunTimestamp)
+		}
+		return downloader.New(r.apiClient).Do(ctx, r.ListTask())
+	}
+
+	// downloading events in incremental mode is handled by the bundle that this resource is a part of
+	return backfill(ctx, r.apiClient, r.ListTask(), runContext)
+}
+
+func (r *Review) StartConsumer(ctx context.Context, ch <-chan api.Object) {
+	defer close(r.msgs)
+	for obj := range ch {
+		switch tr.GetString(obj, "object") {
+		case "event":
+			if payload := tr.ExtractEventPayload(obj, "review"); payload != nil {
+				r.consumeReview(payload, true)
+			}
+		case "review":
+			r.consumeReview(obj, false)
+		}
+	}
+}
+
+func (r *Review) consumeReview(obj api.Object, fromEvent bool) {
+	if msg := r.transform(obj); msg != nil && !(fromEvent && r.dedupe.SeenBefore(msg.ID)) {
+		r.msgs <- *msg
+	}
+}
+
+func (r *Review) transform(obj api.Object) *source.SetMessage {
+	var id string
+	if id = tr.GetString(obj, "id"); id == "" {
+		return nil
+	}
+
+	properties := map[string]interface{}{
+		"charge_id":         tr.GetId(obj, "charge"),
+		"closed_reason":     obj["closed_reason"],
+		"ip_address":        obj["ip_address"],
+		"open":              obj["open"],
+		"opened_reason":     obj["opened_reason"],
+		"payment_intent_id": tr.GetId(obj, "payment_intent"),
+		"reason":            obj["reason"],
+	}
+
+	tr.Flatten(tr.GetMap(obj, "ip_address_location"), "ip_address_location_", properties)
+	tr.Flatten(tr.GetMap(obj, "session"), "session_", properties)
+
+	if created := tr.GetTimestamp(obj, "created"); created != "" {
+		properties["created"] = created
+	}
+
+	return &source.SetMessage{
+		ID:         id,
+		Collection: r.name,
+		Properties: properties,
+	}
+}
+
+func (r *Review) Collection() string {
+	return r.name
+}
+
+func (r *Review) Objects() <-chan api.Object {
+	return r.objs
+}
+
+func (r *Review) Messages() <-chan source.SetMessage {
+	return r.msgs
+}
+
+func (r *Review) CollectionErrors() <-chan integration.CollectionError {
+	return r.errs
+}
+
+func (r *Review) Consumers() []integration.Consumer {
+	return []integration.Consumer{r}
+}
+
+func (r *Review) Close() {
+	r.dedupe.Close()
+}
+
+func NewReview(apiClient api.Client) *Review {
+	return &Review{
+		name:      "reviews",
+		apiClient: apiClient,
+		objs:      make(chan api.Object, 1000),
+		msgs:      make(chan source.SetMessage),
+		errs:      make(chan integration.CollectionError),
+		dedupe:    dedupe.New(),
+	}
+}
@@ -0,0 +1,21 @@
+package resource
+
+import (
+	"github.com/segment-sources/stripe/resource/bundle"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestReviewsAreNotReconciled(t *testing.T) {
+	// reviews are bundled like in main.go
+	b := bundle.New(nil, 0,
+		NewReview(nil),
+		NewEarlyFraudWarning(nil),
+		NewRadarValueList(nil),
+		NewRadarValueListItem(nil),
+	)
+	defer b.Close()
+
+	// /v1/reviews only lists open reviews, closed ones would be deleted by reconciliation
+	assert.Equal(t, []string{"reviews"}, b.PartialCollections())
+}
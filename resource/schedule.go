@@ -0,0 +1,8 @@
+package resource
+
+// Priorities of producers' shares of the request rate, producers without a schedule have priority 1.
+// Small reference collections go first so that dimension tables are usable early in a long first sync.
+const (
+	referencePriority = 4
+	dimensionPriority = 2
+)
@@ -0,0 +1,50 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"github.com/segment-sources/stripe/api"
+	"github.com/segment-sources/stripe/integration"
+	"github.com/segment-sources/stripe/resource/downloader"
+	"github.com/segment-sources/stripe/resource/tasks"
+	"net/url"
+	"time"
+)
+
+// searchIncremental is used instead of a full sync if the run context is older than the events retention.
+// Objects created since the previous run are found using the Search API with the same post-processors as
+// in listTask, updates of older objects are received from the events that are still retained.
+func searchIncremental(ctx context.Context, apiClient api.Client, res integration.Resource, listTask *downloader.Task, endpoint string, previousRunTimestamp time.Time) error {
+	d := downloader.New(apiClient)
+
+	searchTask := *listTask
+	searchTask.Slicing = nil
+	searchTask.Request = &api.Request{
+		Url: endpoint,
+		Qs: url.Values{
+			"limit": []string{"100"},
+			"query": []string{fmt.Sprintf("created>%d", previousRunTimestamp.Add(-time.Hour).Unix())},
+		},
+		LogCollection: listTask.Collection,
+	}
+	if err := d.Do(ctx, &searchTask); err != nil {
+		return err
+	}
+
+	return replayRetainedEvents(ctx, d, res, listTask, previousRunTimestamp)
+}
+
+// replayRetainedEvents downloads events of the list task's collection since the previous run,
+// or since the oldest retained event if the previous run is older than that. Members of bundles
+// leave it to the bundle, which replays the events of every member including derived collections.
+func replayRetainedEvents(ctx context.Context, d *downloader.Client, res integration.Resource, listTask *downloader.Task, previousRunTimestamp time.Time) error {
+	if tasks.BundledEvents(ctx) {
+		return nil
+	}
+
+	task := tasks.MakeIncremental(res, listTask.Collection, tasks.RetainedSince(previousRunTimestamp), listTask.Output, listTask.Errors)
+	if task == nil {
+		return nil
+	}
+	return d.Do(ctx, task)
+}
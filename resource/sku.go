@@ -35,6 +35,14 @@ func (r *Sku) DesiredEvents() []string {
 	return skuEvents
 }
 
+func (r *Sku) ListEndpoint() string {
+	return "/v1/skus"
+}
+
+func (r *Sku) Schedule() integration.Schedule {
+	return integration.Schedule{Priority: referencePriority}
+}
+
 func (r *Sku) StartProducer(ctx context.Context, runContext integration.RunContext) error {
 	defer close(r.objs)
 	defer close(r.errs)
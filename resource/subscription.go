@@ -27,6 +27,7 @@ type Subscription struct {
 	msgs      chan source.SetMessage
 	errs      chan integration.CollectionError
 	dedupe    dedupe.Interface
+	search    bool
 }
 
 func (r *Subscription) DesiredObjects() []string {
@@ -37,26 +38,46 @@ func (r *Subscription) DesiredEvents() []string {
 	return subscriptionEvents
 }
 
+func (r *Subscription) ListEndpoint() string {
+	return "/v1/subscriptions"
+}
+
+// Schedule starts downloading subscriptions once the customers and plans they refer to are synced
+func (r *Subscription) Schedule() integration.Schedule {
+	return integration.Schedule{After: []string{"customers", "plans"}}
+}
+
+func (r *Subscription) ListTask() *downloader.Task {
+	return &downloader.Task{
+		Collection: r.name,
+		Request: &api.Request{
+			Url:           "/v1/subscriptions?status=all&limit=100",
+			LogCollection: r.name,
+		},
+		Output: r.objs,
+		Errors: r.errs,
+		PostProcessors: []downloader.PostProcessor{
+			processors.NewListExpander("items", r.apiClient),
+		},
+	}
+}
+
 func (r *Subscription) StartProducer(ctx context.Context, runContext integration.RunContext) error {
 	defer close(r.objs)
 	defer close(r.errs)
 	if runContext.PreviousRunTimestamp.IsZero() {
-		return downloader.New(r.apiClient).Do(ctx, &downloader.Task{
-			Collection: r.name,
-			Request: &api.Request{
-				Url:           "/v1/subscriptions?status=all&limit=100",
-				LogCollection: r.name,
-			},
-			Output: r.objs,
-			Errors: r.errs,
-			PostProcessors: []downloader.PostProcessor{
-				processors.NewListExpander("items", r.apiClient),
-			},
-		})
+		task := r.ListTask()
+		if r.search && !runContext.StaleRunTimestamp.IsZero() {
+			return searchIncremental(ctx, r.apiClient, r, task, "/v1/subscriptions/search", runContext.StaleRunTimestamp)
+		}
+		if !runContext.StaleRunTimestamp.IsZero() {
+			return partialResync(ctx, r.apiClient, r, task, runContext.StaleRunTimestamp)
+		}
+		return downloader.New(r.apiClient).Do(ctx, task)
 	}
 
 	// downloading events in incremental mode is handled by the bundle that this resource is a part of
-	return nil
+	return backfill(ctx, r.apiClient, r.ListTask(), runContext)
 }
 
 func (r *Subscription) GetEventProcessors() []downloader.PostProcessor {
@@ -94,7 +115,7 @@ func (r *Subscription) transform(obj api.Object) *source.SetMessage {
 	properties := map[string]interface{}{
 		"application_fee_percent": obj["application_fee_percent"],
 		"cancel_at_period_end":    obj["cancel_at_period_end"],
-		"customer_id":             obj["customer"],
+		"customer_id":             tr.GetId(obj, "customer"),
 		"quantity":                obj["quantity"],
 		"status":                  obj["status"],
 		"tax_percent":             obj["tax_percent"],
@@ -165,7 +186,8 @@ func (r *Subscription) Close() {
 	r.dedupe.Close()
 }
 
-func NewSubscription(apiClient api.Client) *Subscription {
+// NewSubscription searches for subscriptions created since a stale previous run instead of a full sync if search is set
+func NewSubscription(apiClient api.Client, search bool) *Subscription {
 	return &Subscription{
 		name:      "subscriptions",
 		apiClient: apiClient,
@@ -173,5 +195,6 @@ func NewSubscription(apiClient api.Client) *Subscription {
 		msgs:      make(chan source.SetMessage),
 		errs:      make(chan integration.CollectionError),
 		dedupe:    dedupe.New(),
+		search:    search,
 	}
 }
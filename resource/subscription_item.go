@@ -5,6 +5,8 @@ import (
 	"github.com/segment-sources/stripe/api"
 	"github.com/segment-sources/stripe/integration"
 	"github.com/segment-sources/stripe/resource/dedupe"
+	"github.com/segment-sources/stripe/resource/downloader"
+	"github.com/segment-sources/stripe/resource/processors"
 	"github.com/segment-sources/stripe/resource/tr"
 	"github.com/segmentio/go-source"
 )
@@ -33,6 +35,13 @@ func (r *SubscriptionItem) StartProducer(ctx context.Context, runContext integra
 	return nil
 }
 
+// GetEventProcessors marks cancelled subscriptions as deleted, so that their items are deleted too
+func (r *SubscriptionItem) GetEventProcessors() []downloader.PostProcessor {
+	return []downloader.PostProcessor{
+		processors.NewIsDeleted("customer.subscription.deleted"),
+	}
+}
+
 func (r *SubscriptionItem) StartConsumer(ctx context.Context, ch <-chan api.Object) {
 	defer close(r.msgs)
 	for obj := range ch {
@@ -40,6 +49,7 @@ func (r *SubscriptionItem) StartConsumer(ctx context.Context, ch <-chan api.Obje
 		case "event":
 			if payload := tr.ExtractEventPayload(obj, "subscription"); payload != nil {
 				r.consumeSubscription(payload, true)
+				r.consumeRemovedItems(obj, payload)
 			}
 		case "subscription":
 			r.consumeSubscription(obj, false)
@@ -53,8 +63,26 @@ func (r *SubscriptionItem) consumeSubscription(obj api.Object, fromEvent bool) {
 		return
 	}
 
+	deleted := tr.GetBool(obj, "is_deleted")
 	for _, line := range tr.GetMapList(tr.GetMap(obj, "items"), "data") {
 		if msg := r.transform(obj, line); msg != nil {
+			if fromEvent {
+				// items of the most recent version aren't deleted by older events that removed them
+				r.dedupe.SeenBefore(msg.ID)
+			}
+			if deleted {
+				msg.Properties["is_deleted"] = true
+			}
+			r.msgs <- *msg
+		}
+	}
+}
+
+// consumeRemovedItems deletes items that were removed from a subscription by an update event
+func (r *SubscriptionItem) consumeRemovedItems(event api.Object, obj api.Object) {
+	for _, item := range removedItems(event, obj, "items") {
+		if msg := r.transform(obj, item); msg != nil && !r.dedupe.SeenBefore(msg.ID) {
+			msg.Properties["is_deleted"] = true
 			r.msgs <- *msg
 		}
 	}
@@ -86,6 +114,10 @@ func (r *SubscriptionItem) transform(subscription, item api.Object) *source.SetM
 		}
 	}
 
+	if price := tr.GetMap(item, "price"); price != nil {
+		properties["price_id"] = price["id"]
+	}
+
 	return &source.SetMessage{
 		ID:         itemId,
 		Collection: r.name,
@@ -1,6 +1,7 @@
 package tasks
 
 import (
+	"context"
 	"fmt"
 	"github.com/segment-sources/stripe/api"
 	"github.com/segment-sources/stripe/integration"
@@ -14,6 +15,60 @@ type HasEventProcessors interface {
 	GetEventProcessors() []downloader.PostProcessor
 }
 
+// HasListEndpoint is implemented by resources that download their objects from a list endpoint
+// rather than deriving them from other resources' objects
+type HasListEndpoint interface {
+	ListEndpoint() string
+}
+
+// eventRetention is how long Stripe keeps events, it's the default of the dispatcher's stale context threshold
+const eventRetention = time.Hour * 24 * 30
+
+type bundledEventsKey struct{}
+
+// RetainedSince returns the previous run's timestamp, or the time of the oldest retained event
+// if the previous run is older than that
+func RetainedSince(previousRunTimestamp time.Time) time.Time {
+	// MakeIncremental requests events created up to an hour before the timestamp
+	if retainedSince := time.Now().UTC().Add(-eventRetention).Add(time.Hour); previousRunTimestamp.Before(retainedSince) {
+		return retainedSince
+	}
+	return previousRunTimestamp
+}
+
+// WithBundledEvents returns a context in which resources don't download their own events, since
+// the bundle that they're members of downloads the events of all members in a single stream
+func WithBundledEvents(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bundledEventsKey{}, true)
+}
+
+// BundledEvents returns true if events are downloaded by the bundle of the resource
+func BundledEvents(ctx context.Context) bool {
+	bundled, _ := ctx.Value(bundledEventsKey{}).(bool)
+	return bundled
+}
+
+// CreatedBetween bounds a list task to objects created in [from, to), a zero to isn't bounded
+func CreatedBetween(task *downloader.Task, from time.Time, to time.Time) *downloader.Task {
+	bounded := *task
+	bounded.Request = &api.Request{
+		Url:           task.Request.Url,
+		Qs:            url.Values{},
+		Params:        task.Request.Params,
+		Expand:        task.Request.Expand,
+		Headers:       task.Request.Headers,
+		LogCollection: task.Request.LogCollection,
+	}
+	for key, value := range task.Request.Qs {
+		bounded.Request.Qs[key] = value
+	}
+	bounded.Request.Qs.Set("created[gte]", fmt.Sprintf("%d", from.Unix()))
+	if !to.IsZero() {
+		bounded.Request.Qs.Set("created[lt]", fmt.Sprintf("%d", to.Unix()))
+	}
+	return &bounded
+}
+
 // MakeIncremental is a shortcut for creating a downloader.Task
 func MakeIncremental(res integration.Resource, collection string, previousRunTimestamp time.Time, ch chan api.Object, errs chan integration.CollectionError) *downloader.Task {
 	allEventsSet := map[string]bool{}
@@ -0,0 +1,115 @@
+package resource
+
+import (
+	"context"
+	"github.com/segment-sources/stripe/api"
+	"github.com/segment-sources/stripe/integration"
+	"github.com/segment-sources/stripe/resource/dedupe"
+	"github.com/segment-sources/stripe/resource/downloader"
+	"github.com/segment-sources/stripe/resource/tr"
+	"github.com/segmentio/go-source"
+)
+
+type TerminalConfiguration struct {
+	name      string
+	apiClient api.Client
+	objs      chan api.Object
+	msgs      chan source.SetMessage
+	errs      chan integration.CollectionError
+	dedupe    dedupe.Interface
+}
+
+func (r *TerminalConfiguration) DesiredObjects() []string {
+	return []string{"terminal.configuration"}
+}
+
+func (r *TerminalConfiguration) DesiredEvents() []string {
+	return nil
+}
+
+func (r *TerminalConfiguration) ListEndpoint() string {
+	return "/v1/terminal/configurations"
+}
+
+// StartProducer for TerminalConfiguration always performs a full sync since Stripe doesn't emit terminal configuration events
+func (r *TerminalConfiguration) StartProducer(ctx context.Context, runContext integration.RunContext) error {
+	defer close(r.objs)
+	defer close(r.errs)
+	return downloader.New(r.apiClient).Do(ctx, &downloader.Task{
+		Collection: r.name,
+		Request: &api.Request{
+			Url:           "/v1/terminal/configurations?limit=100",
+			LogCollection: r.name,
+		},
+		Output: r.objs,
+		Errors: r.errs,
+	})
+}
+
+func (r *TerminalConfiguration) StartConsumer(ctx context.Context, ch <-chan api.Object) {
+	defer close(r.msgs)
+	for obj := range ch {
+		if msg := r.transform(obj); msg != nil {
+			r.msgs <- *msg
+		}
+	}
+}
+
+func (r *TerminalConfiguration) transform(obj api.Object) *source.SetMessage {
+	var id string
+	if id = tr.GetString(obj, "id"); id == "" {
+		return nil
+	}
+
+	properties := map[string]interface{}{
+		"is_account_default": obj["is_account_default"],
+	}
+
+	if device := tr.GetMap(obj, "bbpos_wisepos_e"); device != nil {
+		properties["bbpos_wisepos_e_splashscreen"] = device["splashscreen"]
+	}
+	if device := tr.GetMap(obj, "verifone_p400"); device != nil {
+		properties["verifone_p400_splashscreen"] = device["splashscreen"]
+	}
+
+	return &source.SetMessage{
+		ID:         id,
+		Collection: r.name,
+		Properties: properties,
+	}
+}
+
+func (r *TerminalConfiguration) Collection() string {
+	return r.name
+}
+
+func (r *TerminalConfiguration) Objects() <-chan api.Object {
+	return r.objs
+}
+
+func (r *TerminalConfiguration) Messages() <-chan source.SetMessage {
+	return r.msgs
+}
+
+func (r *TerminalConfiguration) CollectionErrors() <-chan integration.CollectionError {
+	return r.errs
+}
+
+func (r *TerminalConfiguration) Consumers() []integration.Consumer {
+	return []integration.Consumer{r}
+}
+
+func (r *TerminalConfiguration) Close() {
+	r.dedupe.Close()
+}
+
+func NewTerminalConfiguration(apiClient api.Client) *TerminalConfiguration {
+	return &TerminalConfiguration{
+		name:      "terminal_configurations",
+		apiClient: apiClient,
+		objs:      make(chan api.Object, 1000),
+		msgs:      make(chan source.SetMessage),
+		errs:      make(chan integration.CollectionError),
+		dedupe:    dedupe.New(),
+	}
+}
@@ -0,0 +1,112 @@
+package resource
+
+import (
+	"context"
+	"github.com/segment-sources/stripe/api"
+	"github.com/segment-sources/stripe/integration"
+	"github.com/segment-sources/stripe/resource/dedupe"
+	"github.com/segment-sources/stripe/resource/downloader"
+	"github.com/segment-sources/stripe/resource/tr"
+	"github.com/segmentio/go-source"
+)
+
+type TerminalLocation struct {
+	name      string
+	apiClient api.Client
+	objs      chan api.Object
+	msgs      chan source.SetMessage
+	errs      chan integration.CollectionError
+	dedupe    dedupe.Interface
+}
+
+func (r *TerminalLocation) DesiredObjects() []string {
+	return []string{"terminal.location"}
+}
+
+func (r *TerminalLocation) DesiredEvents() []string {
+	return nil
+}
+
+func (r *TerminalLocation) ListEndpoint() string {
+	return "/v1/terminal/locations"
+}
+
+// StartProducer for TerminalLocation always performs a full sync since Stripe doesn't emit terminal location events
+func (r *TerminalLocation) StartProducer(ctx context.Context, runContext integration.RunContext) error {
+	defer close(r.objs)
+	defer close(r.errs)
+	return downloader.New(r.apiClient).Do(ctx, &downloader.Task{
+		Collection: r.name,
+		Request: &api.Request{
+			Url:           "/v1/terminal/locations?limit=100",
+			LogCollection: r.name,
+		},
+		Output: r.objs,
+		Errors: r.errs,
+	})
+}
+
+func (r *TerminalLocation) StartConsumer(ctx context.Context, ch <-chan api.Object) {
+	defer close(r.msgs)
+	for obj := range ch {
+		if msg := r.transform(obj); msg != nil {
+			r.msgs <- *msg
+		}
+	}
+}
+
+func (r *TerminalLocation) transform(obj api.Object) *source.SetMessage {
+	var id string
+	if id = tr.GetString(obj, "id"); id == "" {
+		return nil
+	}
+
+	properties := map[string]interface{}{
+		"configuration_overrides": obj["configuration_overrides"],
+		"display_name":            obj["display_name"],
+	}
+
+	tr.Flatten(tr.GetMap(obj, "address"), "address_", properties)
+	tr.Flatten(tr.GetMap(obj, "metadata"), "metadata_", properties)
+
+	return &source.SetMessage{
+		ID:         id,
+		Collection: r.name,
+		Properties: properties,
+	}
+}
+
+func (r *TerminalLocation) Collection() string {
+	return r.name
+}
+
+func (r *TerminalLocation) Objects() <-chan api.Object {
+	return r.objs
+}
+
+func (r *TerminalLocation) Messages() <-chan source.SetMessage {
+	return r.msgs
+}
+
+func (r *TerminalLocation) CollectionErrors() <-chan integration.CollectionError {
+	return r.errs
+}
+
+func (r *TerminalLocation) Consumers() []integration.Consumer {
+	return []integration.Consumer{r}
+}
+
+func (r *TerminalLocation) Close() {
+	r.dedupe.Close()
+}
+
+func NewTerminalLocation(apiClient api.Client) *TerminalLocation {
+	return &TerminalLocation{
+		name:      "terminal_locations",
+		apiClient: apiClient,
+		objs:      make(chan api.Object, 1000),
+		msgs:      make(chan source.SetMessage),
+		errs:      make(chan integration.CollectionError),
+		dedupe:    dedupe.New(),
+	}
+}
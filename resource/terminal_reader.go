@@ -0,0 +1,123 @@
+package resource
+
+import (
+	"context"
+	"github.com/segment-sources/stripe/api"
+	"github.com/segment-sources/stripe/integration"
+	"github.com/segment-sources/stripe/resource/dedupe"
+	"github.com/segment-sources/stripe/resource/downloader"
+	"github.com/segment-sources/stripe/resource/tr"
+	"github.com/segmentio/go-source"
+)
+
+type TerminalReader struct {
+	name      string
+	apiClient api.Client
+	objs      chan api.Object
+	msgs      chan source.SetMessage
+	errs      chan integration.CollectionError
+	dedupe    dedupe.Interface
+}
+
+func (r *TerminalReader) DesiredObjects() []string {
+	return []string{"terminal.reader"}
+}
+
+func (r *TerminalReader) DesiredEvents() []string {
+	return nil
+}
+
+func (r *TerminalReader) ListEndpoint() string {
+	return "/v1/terminal/readers"
+}
+
+// StartProducer for TerminalReader always performs a full sync, Stripe only emits events for reader actions
+// and the latest action is included in listed readers
+func (r *TerminalReader) StartProducer(ctx context.Context, runContext integration.RunContext) error {
+	defer close(r.objs)
+	defer close(r.errs)
+	return downloader.New(r.apiClient).Do(ctx, &downloader.Task{
+		Collection: r.name,
+		Request: &api.Request{
+			Url:           "/v1/terminal/readers?limit=100",
+			LogCollection: r.name,
+		},
+		Output: r.objs,
+		Errors: r.errs,
+	})
+}
+
+func (r *TerminalReader) StartConsumer(ctx context.Context, ch <-chan api.Object) {
+	defer close(r.msgs)
+	for obj := range ch {
+		if msg := r.transform(obj); msg != nil {
+			r.msgs <- *msg
+		}
+	}
+}
+
+func (r *TerminalReader) transform(obj api.Object) *source.SetMessage {
+	var id string
+	if id = tr.GetString(obj, "id"); id == "" {
+		return nil
+	}
+
+	properties := map[string]interface{}{
+		"device_sw_version": obj["device_sw_version"],
+		"device_type":       obj["device_type"],
+		"ip_address":        obj["ip_address"],
+		"label":             obj["label"],
+		"location_id":       obj["location"],
+		"serial_number":     obj["serial_number"],
+		"status":            obj["status"],
+	}
+
+	if action := tr.GetMap(obj, "action"); action != nil {
+		properties["action_status"] = action["status"]
+		properties["action_type"] = action["type"]
+		properties["action_failure_code"] = action["failure_code"]
+	}
+
+	tr.Flatten(tr.GetMap(obj, "metadata"), "metadata_", properties)
+
+	return &source.SetMessage{
+		ID:         id,
+		Collection: r.name,
+		Properties: properties,
+	}
+}
+
+func (r *TerminalReader) Collection() string {
+	return r.name
+}
+
+func (r *TerminalReader) Objects() <-chan api.Object {
+	return r.objs
+}
+
+func (r *TerminalReader) Messages() <-chan source.SetMessage {
+	return r.msgs
+}
+
+func (r *TerminalReader) CollectionErrors() <-chan integration.CollectionError {
+	return r.errs
+}
+
+func (r *TerminalReader) Consumers() []integration.Consumer {
+	return []integration.Consumer{r}
+}
+
+func (r *TerminalReader) Close() {
+	r.dedupe.Close()
+}
+
+func NewTerminalReader(apiClient api.Client) *TerminalReader {
+	return &TerminalReader{
+		name:      "terminal_readers",
+		apiClient: apiClient,
+		objs:      make(chan api.Object, 1000),
+		msgs:      make(chan source.SetMessage),
+		errs:      make(chan integration.CollectionError),
+		dedupe:    dedupe.New(),
+	}
+}
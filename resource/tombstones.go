@@ -0,0 +1,30 @@
+package resource
+
+import (
+	"github.com/segment-sources/stripe/api"
+	"github.com/segment-sources/stripe/resource/tr"
+)
+
+// removedItems returns items of a parent's embedded list that were removed by an update event, i.e. items of
+// the list's previous version that aren't in the current one. Lists with more items than were embedded are
+// skipped since an item that's missing from them might not have been removed.
+func removedItems(event api.Object, parent api.Object, key string) []api.Object {
+	previous := tr.GetMap(tr.ExtractPreviousAttributes(event), key)
+	current := tr.GetMap(parent, key)
+	if previous == nil || current == nil || tr.GetBool(previous, "has_more") || tr.GetBool(current, "has_more") {
+		return nil
+	}
+
+	currentIds := map[string]bool{}
+	for _, item := range tr.GetMapList(current, "data") {
+		currentIds[tr.GetString(item, "id")] = true
+	}
+
+	removed := []api.Object{}
+	for _, item := range tr.GetMapList(previous, "data") {
+		if id := tr.GetString(item, "id"); id != "" && !currentIds[id] {
+			removed = append(removed, item)
+		}
+	}
+	return removed
+}
@@ -0,0 +1,174 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/segment-sources/stripe/api"
+	"github.com/segment-sources/stripe/integration"
+	"github.com/segment-sources/stripe/resource/processors"
+	"github.com/segment-sources/stripe/resource/tr"
+	"github.com/segmentio/go-source"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func itemList(ids ...string) map[string]interface{} {
+	data := []interface{}{}
+	for _, id := range ids {
+		data = append(data, map[string]interface{}{"id": id, "object": "subscription_item"})
+	}
+	return map[string]interface{}{"object": "list", "data": data, "has_more": false}
+}
+
+func subscriptionEvent(eventType string, items map[string]interface{}, previousItems map[string]interface{}) api.Object {
+	data := map[string]interface{}{
+		"object": map[string]interface{}{"id": "sub_1", "object": "subscription", "items": items},
+	}
+	if previousItems != nil {
+		data["previous_attributes"] = map[string]interface{}{"items": previousItems}
+	}
+	event := api.Object{"id": "evt_" + eventType, "object": "event", "type": eventType, "data": data}
+
+	// the bundle runs event processors of its members before the events are consumed
+	isDeleted := processors.NewIsDeleted("customer.subscription.deleted")
+	isDeleted(context.Background(), event, nil)
+	return event
+}
+
+// consume returns messages of a consumer for objects sorted from the newest like events are
+func consume(consumer integration.Consumer, objs ...api.Object) map[string]source.SetMessage {
+	ch := make(chan api.Object, len(objs))
+	for _, obj := range objs {
+		ch <- obj
+	}
+	close(ch)
+
+	done := make(chan struct{})
+	msgs := map[string]source.SetMessage{}
+	go func() {
+		defer close(done)
+		for msg := range consumer.Messages() {
+			msgs[msg.ID] = msg
+		}
+	}()
+	consumer.StartConsumer(context.Background(), ch)
+	<-done
+	return msgs
+}
+
+func TestRemovedItems(t *testing.T) {
+	a := assert.New(t)
+
+	event := subscriptionEvent("customer.subscription.updated", itemList("si_2"), itemList("si_1", "si_2"))
+	removed := removedItems(event, tr.ExtractEventPayload(event), "items")
+	if a.Len(removed, 1) {
+		a.Equal("si_1", removed[0]["id"])
+	}
+
+	truncated := itemList("si_1", "si_2")
+	truncated["has_more"] = true
+	event = subscriptionEvent("customer.subscription.updated", itemList("si_2"), truncated)
+	a.Empty(removedItems(event, tr.ExtractEventPayload(event), "items"))
+}
+
+func TestSubscriptionItemTombstones(t *testing.T) {
+	consumer := NewSubscriptionItem(nil)
+	defer consumer.Close()
+
+	msgs := consume(consumer,
+		subscriptionEvent("customer.subscription.updated", itemList("si_2", "si_3"), itemList("si_1", "si_2")),
+		// an older version that removed an item which is in the most recent version
+		subscriptionEvent("customer.subscription.updated", itemList("si_1", "si_2"), itemList("si_1", "si_2", "si_3")),
+	)
+
+	a := assert.New(t)
+	a.Len(msgs, 3)
+	a.Equal(true, msgs["si_1"].Properties["is_deleted"])
+	a.Nil(msgs["si_2"].Properties["is_deleted"])
+	a.Nil(msgs["si_3"].Properties["is_deleted"])
+}
+
+func TestSubscriptionItemsOfDeletedSubscription(t *testing.T) {
+	consumer := NewSubscriptionItem(nil)
+	defer consumer.Close()
+
+	msgs := consume(consumer,
+		subscriptionEvent("customer.subscription.deleted", itemList("si_1", "si_2"), nil),
+	)
+
+	a := assert.New(t)
+	a.Len(msgs, 2)
+	a.Equal(true, msgs["si_1"].Properties["is_deleted"])
+	a.Equal(true, msgs["si_2"].Properties["is_deleted"])
+}
+
+func TestCardsOfDeletedCustomer(t *testing.T) {
+	consumer := NewCard(nil)
+	defer consumer.Close()
+
+	event := api.Object{
+		"object": "event",
+		"type":   "customer.deleted",
+		"data": map[string]interface{}{
+			"object": map[string]interface{}{
+				"id":     "cus_1",
+				"object": "customer",
+				"sources": map[string]interface{}{
+					"object": "list",
+					"data": []interface{}{
+						map[string]interface{}{"id": "card_1", "object": "card", "customer": "cus_1"},
+					},
+				},
+			},
+		},
+	}
+	for _, processor := range consumer.GetEventProcessors() {
+		processor(context.Background(), event, nil)
+	}
+	msgs := consume(consumer, event)
+
+	assert.Equal(t, true, msgs["card_1"].Properties["is_deleted"])
+}
+
+func TestInvoiceLineOfDeletedInvoiceItem(t *testing.T) {
+	consumer := NewInvoiceLine(nil)
+	defer consumer.Close()
+
+	period := map[string]interface{}{"start": json.Number("1600000000"), "end": json.Number("1600000000")}
+	line := consumer.transform(
+		api.Object{"id": "in_1"},
+		api.Object{"id": "ii_1", "object": "line_item", "type": "invoiceitem", "period": period},
+	)
+	if !assert.NotNil(t, line) {
+		return
+	}
+
+	event := api.Object{
+		"id":     "evt_1",
+		"object": "event",
+		"type":   "invoiceitem.deleted",
+		"data": map[string]interface{}{
+			"object": map[string]interface{}{
+				"id":      "ii_1",
+				"object":  "invoiceitem",
+				"invoice": "in_1",
+				"period":  period,
+			},
+		},
+	}
+	pending := api.Object{
+		"id":     "evt_2",
+		"object": "event",
+		"type":   "invoiceitem.deleted",
+		"data": map[string]interface{}{
+			"object": map[string]interface{}{"id": "ii_2", "object": "invoiceitem", "invoice": nil, "period": period},
+		},
+	}
+	msgs := consume(consumer, event, pending)
+
+	a := assert.New(t)
+	a.Len(msgs, 1)
+	a.Equal(true, msgs[line.ID].Properties["is_deleted"])
+	a.Equal("in_1", msgs[line.ID].Properties["invoice_id"])
+	a.Equal("ii_1", msgs[line.ID].Properties["item_id"])
+}
@@ -63,6 +63,15 @@ func GetMap(obj map[string]interface{}, key string) map[string]interface{} {
 	return nil
 }
 
+// GetId returns the id of an expandable field, which holds either an id or an expanded object
+func GetId(obj map[string]interface{}, key string) interface{} {
+	if expanded, ok := obj[key].(map[string]interface{}); ok {
+		return expanded["id"]
+	}
+
+	return obj[key]
+}
+
 func Flatten(input map[string]interface{}, prefix string, output map[string]interface{}) {
 	for key, value := range input {
 		if innerMap, ok := value.(map[string]interface{}); ok {
@@ -138,3 +147,8 @@ func ExtractEventPayload(event api.Object, allowedTypes ...string) api.Object {
 
 	return obj
 }
+
+// ExtractPreviousAttributes returns previous values of the attributes changed by an update event
+func ExtractPreviousAttributes(event api.Object) api.Object {
+	return GetMap(GetMap(event, "data"), "previous_attributes")
+}
@@ -26,6 +26,7 @@ type Transfer struct {
 	dedupe            dedupe.Interface
 	processorDedupe   dedupe.Interface
 	enableTransferIds bool
+	processorWorkers  int
 }
 
 func (r *Transfer) DesiredObjects() []string {
@@ -36,32 +37,45 @@ func (r *Transfer) DesiredEvents() []string {
 	return transferEvents
 }
 
+func (r *Transfer) ListEndpoint() string {
+	return "/v1/transfers"
+}
+
+func (r *Transfer) ListTask() *downloader.Task {
+	postProcessors := []downloader.PostProcessor{
+		processors.NewListExpander("reversals", r.apiClient),
+	}
+
+	if r.enableTransferIds {
+		postProcessors = append(postProcessors, processors.NewRelatedTransactions(r.apiClient))
+	}
+
+	return &downloader.Task{
+		Collection: r.name,
+		Request: &api.Request{
+			Url:           "/v1/transfers?limit=100",
+			LogCollection: r.name,
+		},
+		PostProcessors:   postProcessors,
+		ProcessorWorkers: r.processorWorkers,
+		UnorderedOutput:  true,
+		Output:           r.objs,
+		Errors:           r.errs,
+	}
+}
+
 func (r *Transfer) StartProducer(ctx context.Context, runContext integration.RunContext) error {
 	defer close(r.objs)
 	defer close(r.errs)
 	if runContext.PreviousRunTimestamp.IsZero() {
-		postProcessors := []downloader.PostProcessor{
-			processors.NewListExpander("reversals", r.apiClient),
+		if !runContext.StaleRunTimestamp.IsZero() {
+			return partialResync(ctx, r.apiClient, r, r.ListTask(), runContext.StaleRunTimestamp)
 		}
-
-		if r.enableTransferIds {
-			postProcessors = append(postProcessors, processors.NewRelatedTransactions(r.apiClient))
-		}
-
-		return downloader.New(r.apiClient).Do(ctx, &downloader.Task{
-			Collection: r.name,
-			Request: &api.Request{
-				Url:           "/v1/transfers?limit=100",
-				LogCollection: r.name,
-			},
-			PostProcessors: postProcessors,
-			Output:         r.objs,
-			Errors:         r.errs,
-		})
+		return downloader.New(r.apiClient).Do(ctx, r.ListTask())
 	}
 
 	// downloading events in incremental mode is handled by the bundle that this resource is a part of
-	return nil
+	return backfill(ctx, r.apiClient, r.ListTask(), runContext)
 }
 
 func (r *Transfer) StartConsumer(ctx context.Context, ch <-chan api.Object) {
@@ -93,16 +107,16 @@ func (r *Transfer) transform(obj api.Object) *source.SetMessage {
 	properties := map[string]interface{}{
 		"amount":                 obj["amount"],
 		"amount_reversed":        obj["amount_reversed"],
-		"application_fee":        obj["application_fee"],
-		"balance_transaction_id": obj["balance_transaction"],
+		"application_fee":        tr.GetId(obj, "application_fee"),
+		"balance_transaction_id": tr.GetId(obj, "balance_transaction"),
 		"currency":               obj["currency"],
 		"description":            obj["description"],
-		"destination_id":         obj["destination"],
+		"destination_id":         tr.GetId(obj, "destination"),
 		"destination_payment":    obj["destination_payment"],
 		"failure_code":           obj["failure_code"],
 		"failure_message":        obj["failure_message"],
 		"reversed":               obj["reversed"],
-		"source_transaction":     obj["source_transaction"],
+		"source_transaction":     tr.GetId(obj, "source_transaction"),
 		"statement_descriptor":   obj["statement_descriptor"],
 		"status":                 obj["status"],
 		"type":                   obj["type"],
@@ -162,7 +176,7 @@ func (r *Transfer) Close() {
 	r.processorDedupe.Close()
 }
 
-func NewTransfer(apiClient api.Client, enableTransferIds bool) *Transfer {
+func NewTransfer(apiClient api.Client, enableTransferIds bool, processorWorkers int) *Transfer {
 	return &Transfer{
 		name:              "transfers",
 		apiClient:         apiClient,
@@ -172,5 +186,6 @@ func NewTransfer(apiClient api.Client, enableTransferIds bool) *Transfer {
 		dedupe:            dedupe.New(),
 		processorDedupe:   dedupe.New(),
 		enableTransferIds: enableTransferIds,
+		processorWorkers:  processorWorkers,
 	}
 }
@@ -69,8 +69,8 @@ func (r *TransferReversal) transform(obj api.Object) *source.SetMessage {
 	properties := map[string]interface{}{
 		"amount":                 obj["amount"],
 		"currency":               obj["currency"],
-		"balance_transaction_id": obj["balance_transaction"],
-		"transfer_id":            obj["transfer"],
+		"balance_transaction_id": tr.GetId(obj, "balance_transaction"),
+		"transfer_id":            tr.GetId(obj, "transfer"),
 	}
 
 	tr.Flatten(tr.GetMap(obj, "metadata"), "metadata_", properties)
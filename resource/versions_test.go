@@ -0,0 +1,212 @@
+package resource
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/segment-sources/stripe/api"
+	"github.com/segmentio/go-source"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// versionFixtures contain the same customer and subscription as returned by different API versions
+var versionFixtures = map[string]struct {
+	customer     string
+	subscription string
+}{
+	"2016-07-06": {
+		customer: `{
+			"id": "cus_1",
+			"object": "customer",
+			"account_balance": -500,
+			"currency": "usd",
+			"delinquent": false,
+			"email": "jane@example.com",
+			"created": 1600000000,
+			"sources": {
+				"object": "list",
+				"data": [{
+					"id": "card_1",
+					"object": "card",
+					"address_city": "San Francisco",
+					"address_country": "US",
+					"address_line1": "1 Market St",
+					"address_line1_check": "pass",
+					"address_line2": null,
+					"address_state": "CA",
+					"address_zip": "94105",
+					"address_zip_check": "pass",
+					"brand": "Visa",
+					"country": "US",
+					"customer": "cus_1",
+					"cvc_check": "pass",
+					"exp_month": 8,
+					"exp_year": 2030,
+					"fingerprint": "Xt5EWLLDS7FJjR1c",
+					"funding": "credit",
+					"last4": "4242",
+					"name": "Jane Doe",
+					"tokenization_method": null
+				}]
+			}
+		}`,
+		subscription: `{
+			"id": "sub_1",
+			"object": "subscription",
+			"customer": "cus_1",
+			"plan": {"id": "gold", "object": "plan", "amount": 2000, "interval": "month"},
+			"quantity": 1,
+			"items": {
+				"object": "list",
+				"data": [{
+					"id": "si_1",
+					"object": "subscription_item",
+					"created": 1600000000,
+					"quantity": 1,
+					"plan": {"id": "gold", "object": "plan", "amount": 2000, "interval": "month"}
+				}]
+			}
+		}`,
+	},
+	"2020-08-27": {
+		customer: `{
+			"id": "cus_1",
+			"object": "customer",
+			"balance": -500,
+			"currency": "usd",
+			"delinquent": false,
+			"email": "jane@example.com",
+			"created": 1600000000,
+			"sources": {
+				"object": "list",
+				"data": [{
+					"id": "card_1",
+					"object": "payment_method",
+					"type": "card",
+					"customer": "cus_1",
+					"billing_details": {
+						"name": "Jane Doe",
+						"address": {
+							"city": "San Francisco",
+							"country": "US",
+							"line1": "1 Market St",
+							"line2": null,
+							"postal_code": "94105",
+							"state": "CA"
+						}
+					},
+					"card": {
+						"brand": "Visa",
+						"checks": {"address_line1_check": "pass", "address_postal_code_check": "pass", "cvc_check": "pass"},
+						"country": "US",
+						"exp_month": 8,
+						"exp_year": 2030,
+						"fingerprint": "Xt5EWLLDS7FJjR1c",
+						"funding": "credit",
+						"last4": "4242",
+						"wallet": null
+					}
+				}]
+			}
+		}`,
+		subscription: `{
+			"id": "sub_1",
+			"object": "subscription",
+			"customer": "cus_1",
+			"items": {
+				"object": "list",
+				"data": [{
+					"id": "si_1",
+					"object": "subscription_item",
+					"created": 1600000000,
+					"quantity": 1,
+					"price": {
+						"id": "gold",
+						"object": "price",
+						"unit_amount": 2000,
+						"recurring": {"interval": "month", "interval_count": 1}
+					}
+				}]
+			}
+		}`,
+	},
+}
+
+func decodeFixture(t *testing.T, version string, fixture string) api.Object {
+	obj := api.Object{}
+	decoder := json.NewDecoder(bytes.NewReader([]byte(fixture)))
+	decoder.UseNumber()
+	if err := decoder.Decode(&obj); err != nil {
+		t.Fatal(err)
+	}
+	api.NewVersionAdapter(version).Normalize(obj)
+	return obj
+}
+
+func TestCustomerAcrossVersions(t *testing.T) {
+	for version, fixtures := range versionFixtures {
+		customer := decodeFixture(t, version, fixtures.customer)
+
+		customers := NewCustomer(nil, 0, false, false)
+		defer customers.Close()
+		msg := customers.transform(customer)
+		if !assert.NotNil(t, msg, version) {
+			continue
+		}
+		assert.Equal(t, "cus_1", msg.ID, version)
+		assert.Equal(t, json.Number("-500"), msg.Properties["account_balance"], version)
+		assert.Equal(t, "jane@example.com", msg.Properties["email"], version)
+
+		cards := NewCard(nil)
+		defer cards.Close()
+		go cards.consumeCustomer(customer, false)
+		assert.Equal(t, source.SetMessage{
+			ID:         "card_1",
+			Collection: "cards",
+			Properties: map[string]interface{}{
+				"address_city":        "San Francisco",
+				"address_country":     "US",
+				"address_line1":       "1 Market St",
+				"address_line1_check": "pass",
+				"address_line2":       nil,
+				"address_state":       "CA",
+				"address_zip":         "94105",
+				"address_zip_check":   "pass",
+				"brand":               "Visa",
+				"country":             "US",
+				"customer_id":         "cus_1",
+				"cvc_check":           "pass",
+				"dynamic_last4":       nil,
+				"exp_month":           json.Number("8"),
+				"exp_year":            json.Number("2030"),
+				"fingerprint":         "Xt5EWLLDS7FJjR1c",
+				"funding":             "credit",
+				"last4":               "4242",
+				"name":                "Jane Doe",
+				"tokenization_method": nil,
+			},
+		}, <-cards.Messages(), version)
+	}
+}
+
+func TestSubscriptionAcrossVersions(t *testing.T) {
+	for version, fixtures := range versionFixtures {
+		subscription := decodeFixture(t, version, fixtures.subscription)
+
+		subscriptions := NewSubscription(nil, false)
+		defer subscriptions.Close()
+		msg := subscriptions.transform(subscription)
+		if !assert.NotNil(t, msg, version) {
+			continue
+		}
+		assert.Equal(t, "gold", msg.Properties["plan_id"], version)
+		assert.Equal(t, json.Number("1"), msg.Properties["quantity"], version)
+
+		items := NewSubscriptionItem(nil)
+		defer items.Close()
+		go items.consumeSubscription(subscription, false)
+		item := <-items.Messages()
+		assert.Equal(t, "si_1", item.ID, version)
+		assert.Equal(t, "gold", item.Properties["plan_id"], version)
+	}
+}